@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"thermal-throttling-analyzer/internal/advice"
 	"thermal-throttling-analyzer/internal/events"
 
 	"github.com/spf13/cobra"
@@ -43,7 +44,7 @@ var analyzeCmd = &cobra.Command{
 		for _, e := range allEvents {
 			if e.Timestamp.After(startTime) {
 				relevantEvents = append(relevantEvents, e)
-				if e.State == "THROTTLING" {
+				if e.State == "THROTTLING" && e.CPUZone() {
 					throttleCount++
 				}
 				// Parsing temp from details string is brittle but OK for this scope
@@ -53,6 +54,15 @@ var analyzeCmd = &cobra.Command{
 
 		fmt.Printf("Thermal Events (last %s):\n", duration)
 		fmt.Printf("• Throttling events: %d\n", throttleCount)
+		if impact := advice.SummarizeImpact(relevantEvents); impact.Known() {
+			fmt.Printf("• User-visible impact: %s\n", impact)
+		}
+		if power := advice.SummarizePowerSource(relevantEvents); power.Known() {
+			fmt.Printf("• By power source: %s (%d switch(es) between AC and battery)\n", power, power.Switches)
+		}
+		if zones := advice.SummarizeZones(relevantEvents); len(zones) > 0 {
+			fmt.Printf("• Device zones: %s\n", zones)
+		}
 		// Calculation of duration/avg would require pairing start/stop events.
 		// For MVP/CLI scope, counting valid "THROTTLING" log entries (which happen on change) is tricky.
 		// 'watch' logs on state CHANGE.
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// cpuTempSelectors and cpuTempRule hold --cpu-temp and --cpu-temp-rule.
+var (
+	cpuTempSelectors []string
+	cpuTempRule      string
+)
+
+// setupTempMapping validates --cpu-temp and hands the mapping to the collector.
+// Without --cpu-temp the backends keep choosing the CPU sensor themselves.
+func setupTempMapping() error {
+	if len(cpuTempSelectors) == 0 {
+		if cpuTempRule != sensors.TempRuleMax {
+			return fmt.Errorf("--cpu-temp-rule needs --cpu-temp")
+		}
+		return nil
+	}
+	m, err := sensors.ParseTempMapping(cpuTempSelectors, cpuTempRule)
+	if err != nil {
+		return fmt.Errorf("--cpu-temp: %v", err)
+	}
+	sensors.SetTempMapping(m)
+	return nil
+}
+
+// sessionMetadata describes where this run's data comes from and how it is
+// read, for the SESSION_START event. source is "sensors" for a live run.
+func sessionMetadata(source string) map[string]string {
+	meta := map[string]string{"source": source}
+	if source == "sensors" {
+		meta["backend"] = sensorBackend
+		if p, err := sensors.ActiveProvider(); err == nil {
+			meta["backend"] = p.Name()
+		}
+		meta["cpu_temp"] = "auto"
+		if m := sensors.TempMappingInUse(); m != nil {
+			meta["cpu_temp"] = m.String()
+		}
+	}
+	for signal, chain := range filterOverrides {
+		meta["filter."+signal] = chain
+	}
+	return meta
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"thermal-throttling-analyzer/internal/filter"
+	"thermal-throttling-analyzer/internal/scenario"
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// filterSpecs holds the --filter flags, e.g. "TempC=median:5,ema:0.3".
+var filterSpecs []string
+
+// filterOverrides maps signals to the chains given with --filter.
+var filterOverrides = map[string]string{}
+
+// setupFilters validates --filter and configures oversampling in the collector.
+func setupFilters() error {
+	for _, spec := range filterSpecs {
+		signal, chain, ok := strings.Cut(spec, "=")
+		if !ok {
+			return fmt.Errorf("invalid --filter %q, expected SIGNAL=CHAIN (e.g. TempC=median:3,ema:0.5)", spec)
+		}
+		filterOverrides[signal] = chain
+	}
+
+	// Building a pipeline once checks every chain.
+	p, err := buildPipeline(nil)
+	if err != nil {
+		return err
+	}
+	for signal, c := range p.Smoother.Chains() {
+		if c.Oversample > 1 {
+			if err := sensors.SetOversample(signal, c.Oversample); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newPipeline returns a fresh filter pipeline with the configured chains.
+// Filters keep state, so every run gets its own.
+func newPipeline() *filter.Pipeline {
+	p, err := buildPipeline(nil)
+	if err != nil {
+		// setupFilters already validated the configuration.
+		panic(err)
+	}
+	return p
+}
+
+// buildPipeline starts from the chains a scenario declares, if any, and
+// applies --filter on top.
+func buildPipeline(sc *scenario.Scenario) (*filter.Pipeline, error) {
+	p := filter.NewPipeline()
+	if sc != nil {
+		var err error
+		if p, err = sc.Pipeline(); err != nil {
+			return nil, err
+		}
+	}
+	for signal, spec := range filterOverrides {
+		c, err := filter.ParseChain(spec)
+		if err != nil {
+			return nil, fmt.Errorf("--filter %s: %v", signal, err)
+		}
+		if err := p.Smoother.Set(signal, c); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"thermal-throttling-analyzer/internal/events"
@@ -38,11 +40,34 @@ var logCmd = &cobra.Command{
 			// Format: 14:31 TEMP_RISE 89°C
 			// Simplified format as per req
 			timeStr := e.Timestamp.Format("15:04")
-			fmt.Printf("%s %s %s\n", timeStr, e.Type, e.Details)
+			eventType := e.Type
+			if !e.CPUZone() {
+				// Device zone states, e.g. "nvme:THROTTLING"
+				eventType = e.Zone + ":" + e.Type
+			}
+			fmt.Printf("%s %s %s%s\n", timeStr, eventType, e.Details, formatSignals(e.Signals))
 		}
 	},
 }
 
+// formatSignals renders signal provenance as " [TempC=measured/linux ...]".
+func formatSignals(signals map[string]string) string {
+	if len(signals) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(signals))
+	for name := range signals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+signals[name])
+	}
+	return " [" + strings.Join(parts, " ") + "]"
+}
+
 func init() {
 	logCmd.Flags().BoolVar(&logOnlyToday, "today", false, "Show only today's events")
 	rootCmd.AddCommand(logCmd)
@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+
+	"thermal-throttling-analyzer/internal/sensors"
 
 	"github.com/spf13/cobra"
 )
@@ -16,7 +19,25 @@ const (
 var rootCmd = &cobra.Command{
 	Use:   "tta",
 	Short: "Thermal Throttling Analyzer",
-	Long:  `A Windows-only CLI diagnostic tool in Go that detects, analyzes, and explains CPU thermal throttling.`,
+	Long:  `A CLI diagnostic tool in Go for Windows and Linux that detects, analyzes, and explains CPU thermal throttling.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupCommandFixtures(); err != nil {
+			return err
+		}
+		if err := setupFilters(); err != nil {
+			return err
+		}
+		if err := setupTempMapping(); err != nil {
+			return err
+		}
+		if linux, ok := sensors.Lookup("linux").(*sensors.LinuxProvider); ok {
+			if cgroupPath != "" {
+				linux.Cgroup.Path = cgroupPath
+			}
+			linux.MSR.ClearLogs = clearMSRLogs
+		}
+		return sensors.SetBackend(sensorBackend)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		const banner = `
 		
@@ -35,6 +56,53 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+var (
+	sensorBackend  string
+	recordCommands string
+	replayCommands string
+	cgroupPath     string
+	replayTrace    string
+	clearMSRLogs   bool
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&sensorBackend, "sensor-backend", sensors.BackendAuto,
+		fmt.Sprintf("Sensor backend to use (%s, %s)", sensors.BackendAuto, strings.Join(sensors.Backends(), ", ")))
+	rootCmd.PersistentFlags().StringVar(&recordCommands, "record-commands", "", "Save the output of every sensor command into this fixture directory")
+	rootCmd.PersistentFlags().StringVar(&cgroupPath, "cgroup", "", "Cgroup path to check for CPU quota throttling (default: the cgroup tta runs in)")
+	rootCmd.PersistentFlags().BoolVar(&clearMSRLogs, "clear-msr-logs", false, "Clear the thermal status log bits after each read, so they cover one sample (writes MSRs, needs root)")
+	rootCmd.PersistentFlags().StringVar(&replayCommands, "replay-commands", "", "Serve sensor commands from this fixture directory instead of running them")
+	rootCmd.PersistentFlags().StringArrayVar(&filterSpecs, "filter", nil, "Filter chain for a signal, e.g. TempC=median:3,rate:25,ema:0.5 or TempC=off (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&cpuTempSelectors, "cpu-temp", nil, "Sensor to take the CPU temperature from, e.g. hwmon:k10temp/Tctl or wmi:LibreHardwareMonitor/intelcpu/0/CPU Package; patterns allowed (repeatable, see tta sensors)")
+	rootCmd.PersistentFlags().StringVar(&cpuTempRule, "cpu-temp-rule", sensors.TempRuleMax, "How to combine the --cpu-temp sensors: max, mean, or label (the first selector that matches)")
+	rootCmd.PersistentFlags().StringVar(&replayTrace, "replay-trace", "", "Read snapshots from a recorded trace file (.jsonl or .csv) instead of the sensors")
+}
+
+// setupCommandFixtures swaps the sensor command executor for record or replay mode.
+func setupCommandFixtures() error {
+	if recordCommands != "" && replayCommands != "" {
+		return fmt.Errorf("--record-commands and --replay-commands cannot be used together")
+	}
+	if replayCommands != "" {
+		replay, err := sensors.NewReplayExecutor(replayCommands)
+		if err != nil {
+			return err
+		}
+		sensors.SetExecutor(replay)
+	}
+	if recordCommands != "" {
+		// Wrap whatever executor is active so recording sees real outputs.
+		prev := sensors.SetExecutor(nil)
+		recorder, err := sensors.NewRecordingExecutor(prev, recordCommands)
+		if err != nil {
+			sensors.SetExecutor(prev)
+			return err
+		}
+		sensors.SetExecutor(recorder)
+	}
+	return nil
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"thermal-throttling-analyzer/internal/filter"
+	"thermal-throttling-analyzer/internal/scenario"
+
+	"github.com/spf13/cobra"
+)
+
+var scenarioCmd = &cobra.Command{
+	Use:   "scenario",
+	Short: "Synthetic thermal workloads for testing",
+}
+
+var scenarioListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in scenarios",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range scenario.BuiltinNames() {
+			sc, err := scenario.Builtin(name)
+			if err != nil {
+				fmt.Printf("%-16s error: %v\n", name, err)
+				continue
+			}
+			fmt.Printf("%-16s %s\n", name, sc.Description)
+			if len(sc.Expect) > 0 {
+				fmt.Printf("%-16s expects %s\n", "", strings.Join(sc.Expect, " → "))
+			}
+		}
+	},
+}
+
+var scenarioCheckCmd = &cobra.Command{
+	Use:   "check [scenario...]",
+	Short: "Run scenarios through the state machine and compare with their expected states",
+	Long:  "Run scenarios (built-in names or scenario files) through the state machine and compare the states it goes through with each scenario's expect list. Without arguments, every built-in scenario is checked.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			args = scenario.BuiltinNames()
+		}
+		failed := false
+		for _, name := range args {
+			sc, err := scenario.Resolve(name)
+			if err == nil {
+				var p *filter.Pipeline
+				if p, err = buildPipeline(sc); err == nil {
+					err = sc.Check(p)
+				}
+			}
+			if err != nil {
+				fmt.Printf("FAIL %s: %v\n", name, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("ok   %s\n", sc.Name)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	scenarioCmd.AddCommand(scenarioListCmd)
+	scenarioCmd.AddCommand(scenarioCheckCmd)
+	rootCmd.AddCommand(scenarioCmd)
+}
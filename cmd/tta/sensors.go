@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"thermal-throttling-analyzer/internal/sensors"
+
+	"github.com/spf13/cobra"
+)
+
+var sensorsJSON bool
+
+var sensorsCmd = &cobra.Command{
+	Use:   "sensors",
+	Short: "List every sensor the backends can find",
+	Long:  "List the sensors of every available backend (or only the one chosen with --sensor-backend): hwmon chips, thermal zones, cpufreq policies, RAPL domains, WMI classes and counters. Each shows its current value and whether it can be read without root or Administrator rights. Temperature sensors show the selector to pass to --cpu-temp.",
+	Run: func(cmd *cobra.Command, args []string) {
+		results := sensors.DiscoverSensors(context.Background())
+
+		if sensorsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(results) == 0 {
+			fmt.Println("No sensor backend available on this system")
+			return
+		}
+		for i, res := range results {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("Backend %s: %d sensor(s)\n", res.Backend, len(res.Sensors))
+			if res.Error != "" {
+				fmt.Printf("  error: %s\n", res.Error)
+			}
+			if len(res.Sensors) == 0 {
+				continue
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "  KIND\tSOURCE\tNAME\tVALUE\tUNPRIVILEGED\tSELECTOR")
+			for _, s := range res.Sensors {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\n", s.Kind, s.Source, s.Name, formatSensorValue(s), yesNo(s.Unprivileged), s.Selector)
+			}
+			tw.Flush()
+		}
+	},
+}
+
+// formatSensorValue renders the value with its unit, or why it could not be read.
+func formatSensorValue(s sensors.SensorInfo) string {
+	if !s.Readable {
+		if s.Error != "" {
+			return "(" + s.Error + ")"
+		}
+		return "-"
+	}
+	v := strconv.FormatFloat(s.Value, 'f', 1, 64)
+	if s.Value == float64(int64(s.Value)) {
+		v = strconv.FormatInt(int64(s.Value), 10)
+	}
+	if s.Unit != "" {
+		v += " " + s.Unit
+	}
+	return v
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func init() {
+	sensorsCmd.Flags().BoolVar(&sensorsJSON, "json", false, "Print the sensors as JSON")
+	rootCmd.AddCommand(sensorsCmd)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"thermal-throttling-analyzer/internal/analyzer"
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// snapshotSource yields one snapshot per tick: live from the sensors, or from a trace.
+// Next returns io.EOF when a trace has been played to the end.
+type snapshotSource interface {
+	Next(ctx context.Context) (*sensors.Snapshot, error)
+}
+
+// liveSource collects a snapshot every SampleInterval.
+type liveSource struct {
+	ticker   *time.Ticker
+	lastTick time.Time
+
+	// onOverrun is told when handling the previous snapshot took longer than
+	// the sample interval, so samples are no longer evenly spaced.
+	onOverrun func(elapsed time.Duration, missed int)
+}
+
+func newLiveSource(onOverrun func(time.Duration, int)) *liveSource {
+	return &liveSource{ticker: time.NewTicker(analyzer.SampleInterval), onOverrun: onOverrun}
+}
+
+func (l *liveSource) Next(ctx context.Context) (*sensors.Snapshot, error) {
+	var elapsed time.Duration
+	if !l.lastTick.IsZero() {
+		elapsed = time.Since(l.lastTick)
+	}
+
+	var tick time.Time
+	select {
+	case tick = <-l.ticker.C:
+	case <-ctx.Done():
+		l.ticker.Stop()
+		return nil, ctx.Err()
+	}
+
+	// The ticker drops ticks while we are busy, so a long gap means
+	// the previous iteration overran and samples were skipped.
+	missed := 0
+	if !l.lastTick.IsZero() {
+		missed = int(tick.Sub(l.lastTick)/analyzer.SampleInterval) - 1
+	}
+	l.lastTick = tick
+	if (elapsed > analyzer.SampleInterval || missed > 0) && l.onOverrun != nil {
+		l.onOverrun(elapsed, missed)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, analyzer.TickDeadline)
+	defer cancel()
+	return sensors.CollectSnapshot(tctx), nil
+}
+
+// loadReplayTrace reads the trace named by --replay-trace.
+func loadReplayTrace() ([]*sensors.Snapshot, error) {
+	return sensors.ReadTrace(replayTrace)
+}
+
+// stepKeys turns every line typed on stdin into one replay step.
+func stepKeys() <-chan struct{} {
+	steps := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			steps <- struct{}{}
+		}
+	}()
+	return steps
+}
+
+// replayHistory runs a whole trace through the state machines, as watch would
+// have, and returns the final analysis of the CPU and of each device zone.
+func replayHistory(trace []*sensors.Snapshot) (analyzer.AnalysisResult, []analyzer.AnalysisResult, error) {
+	if len(trace) == 0 {
+		return analyzer.AnalysisResult{}, nil, fmt.Errorf("trace has no snapshots")
+	}
+	sm := analyzer.NewStateMachine()
+	zones := analyzer.NewDeviceZones()
+	pipeline := newPipeline()
+	var res analyzer.AnalysisResult
+	var zoneResults []analyzer.AnalysisResult
+	for _, snap := range trace {
+		pipeline.Process(snap)
+		res = sm.UpdateWithHistory(snap)
+		zoneResults = zones.UpdateWithHistory(snap)
+	}
+	return res, zoneResults, nil
+}
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"os"
+	"sort"
+	"strings"
+	"time"
 	"thermal-throttling-analyzer/internal/analyzer"
 	"thermal-throttling-analyzer/internal/sensors"
 	"github.com/spf13/cobra"
@@ -12,13 +17,104 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "What's happening right now?",
 	Run: func(cmd *cobra.Command, args []string) {
-		snapshot := sensors.CollectSnapshot()
-		sm := analyzer.NewStateMachine()
-		result := sm.Update(snapshot)
+		var snapshot *sensors.Snapshot
+		var result analyzer.AnalysisResult
+		var zoneResults []analyzer.AnalysisResult
+		if replayTrace != "" {
+			// The state at the end of the trace, reached through the same
+			// history-aware pipeline watch uses.
+			trace, err := loadReplayTrace()
+			if err != nil {
+				fmt.Printf("Error loading trace: %v\n", err)
+				os.Exit(1)
+			}
+			result, zoneResults, err = replayHistory(trace)
+			if err != nil {
+				fmt.Printf("Error replaying trace: %v\n", err)
+				os.Exit(1)
+			}
+			snapshot = result.Snapshot
+			fmt.Printf("Replayed %d snapshots, state as of %s\n\n", len(trace), snapshot.Timestamp.Format(time.RFC3339))
+		} else {
+			// One-shot: give every backend its full query timeout rather than a tick budget.
+			ctx, cancel := context.WithTimeout(context.Background(), sensors.DefaultQueryTimeout)
+			defer cancel()
+
+			snapshot = sensors.CollectSnapshot(ctx)
+			newPipeline().Process(snapshot)
+			sm := analyzer.NewStateMachine()
+			result = sm.Update(snapshot)
+			zoneResults = analyzer.NewDeviceZones().Update(snapshot)
+		}
 
 		fmt.Printf("Thermal State: %s\n", result.State)
 		fmt.Printf("Reason: %s\n", result.Reason)
 		fmt.Printf("Confidence: %s\n", result.Confidence)
+		if len(result.Causes) > 0 {
+			fmt.Printf("Throttle Cause: %s\n", strings.Join(causeNames(result.Causes), ", "))
+		}
+		if result.Impact != analyzer.ImpactUnknown {
+			fmt.Printf("Impact: %s\n", result.Impact)
+		}
+		ignored := ignoredZones(snapshot)
+		if len(zoneResults) > 0 || len(ignored) > 0 {
+			fmt.Println("\nZones:")
+			for _, zr := range zoneResults {
+				printZone(zr)
+			}
+			for _, zone := range ignored {
+				fmt.Printf("  %-8s not analyzed, no thresholds for this zone\n", zone)
+			}
+		}
+
+		fmt.Println("\nSignals:")
+		printSignal(snapshot, sensors.SignalTemp, fmt.Sprintf("%.1f°C", snapshot.TempC)+rawSuffix(snapshot, sensors.SignalTemp, snapshot.TempC))
+		if len(snapshot.TempSensors) > 0 {
+			fmt.Printf("  %-15s from %s\n", "", strings.Join(snapshot.TempSensors, ", "))
+		}
+		printSignal(snapshot, sensors.SignalFreq, fmt.Sprintf("%d MHz", snapshot.FreqMHz)+rawSuffix(snapshot, sensors.SignalFreq, float64(snapshot.FreqMHz)))
+		printSignal(snapshot, sensors.SignalBaseFreq, fmt.Sprintf("%d MHz", snapshot.BaseFreqMHz))
+		printSignal(snapshot, sensors.SignalLoad, fmt.Sprintf("%.0f%%", snapshot.LoadPercent))
+		if _, ok := snapshot.Sources[sensors.SignalPower]; ok {
+			printSignal(snapshot, sensors.SignalPower, formatPower(snapshot))
+		}
+		if _, ok := snapshot.Sources[sensors.SignalThrottle]; ok {
+			printSignal(snapshot, sensors.SignalThrottle, fmt.Sprintf("%d (%d ms)", snapshot.ThrottleEvents(), snapshot.ThrottleMs()))
+		}
+		if _, ok := snapshot.Sources[sensors.SignalThermStatus]; ok {
+			hw := "clear"
+			if len(snapshot.HWThrottleReasons) > 0 {
+				hw = strings.Join(snapshot.HWThrottleReasons, ", ")
+			}
+			if snapshot.MSRTempC > 0 {
+				hw += fmt.Sprintf(", readout %.0f°C (TjMax %d°C)", snapshot.MSRTempC, snapshot.TjMaxC)
+			}
+			printSignal(snapshot, sensors.SignalThermStatus, hw)
+		}
+		if _, ok := snapshot.Sources[sensors.SignalZones]; ok {
+			zones := "not limiting"
+			if len(snapshot.ZoneThrottleReasons) > 0 {
+				zones = strings.Join(snapshot.ZoneThrottleReasons, ", ")
+			}
+			printSignal(snapshot, sensors.SignalZones, zones)
+		}
+		if _, ok := snapshot.Sources[sensors.SignalFans]; ok {
+			printSignal(snapshot, sensors.SignalFans, formatFans(snapshot.Fans))
+		}
+		if _, ok := snapshot.Sources[sensors.SignalPowerSource]; ok {
+			printSignal(snapshot, sensors.SignalPowerSource, formatPowerSource(snapshot))
+		}
+		if _, ok := snapshot.Sources[sensors.SignalDeviceTemps]; ok {
+			printSignal(snapshot, sensors.SignalDeviceTemps, formatDeviceTemps(snapshot.DeviceTempC))
+		}
+		if _, ok := snapshot.Sources[sensors.SignalQuota]; ok {
+			printSignal(snapshot, sensors.SignalQuota, fmt.Sprintf("%d/%d periods (%.0f ms) in %s",
+				snapshot.CgroupThrottledPeriods, snapshot.CgroupPeriods, snapshot.CgroupThrottledMs, snapshot.CgroupPath))
+		}
+		if _, ok := snapshot.Sources[sensors.SignalPressure]; ok {
+			printSignal(snapshot, sensors.SignalPressure, fmt.Sprintf("some %.1f%% / full %.1f%% (10s), some %.1f%% (60s)",
+				snapshot.PSISomeAvg10, snapshot.PSIFullAvg10, snapshot.PSISomeAvg60))
+		}
 		
 		if len(snapshot.ValidSignals) == 0 {
 			fmt.Println("\nWarning: No sensors could be read. Ensure you are running as Administrator or on a supported Windows device.")
@@ -27,6 +123,103 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// printSignal prints one signal with where its value came from.
+// Missing signals show no value, so a zero is never mistaken for a reading.
+func printSignal(s *sensors.Snapshot, name, value string) {
+	src := s.Source(name)
+	if src.Provenance == sensors.ProvenanceMissing {
+		value = "-"
+	}
+	if reason, ok := s.Rejected[name]; ok {
+		fmt.Printf("  %-15s %-10s (%s: %s)\n", name, value, src, reason)
+		return
+	}
+	fmt.Printf("  %-15s %-10s (%s)\n", name, value, src)
+}
+
+// printZone prints the state of one device zone against the thresholds it was judged by.
+func printZone(res analyzer.AnalysisResult) {
+	t := res.Thresholds
+	temp := "-"
+	if v, ok := res.Snapshot.DeviceTempC[res.Zone]; ok && res.Snapshot.HasSignal(sensors.SignalDeviceTemps) {
+		temp = fmt.Sprintf("%.1f°C", v)
+	}
+	fmt.Printf("  %-8s %-12s %-8s (high %.0f°C, critical %.0f°C) %s\n", res.Zone, res.State, temp, t.HighC, t.CriticalC, res.Reason)
+}
+
+// ignoredZones returns the device zones of s without thresholds, sorted.
+// The analysis skips them rather than judging them by another zone's limits.
+func ignoredZones(s *sensors.Snapshot) []string {
+	var zones []string
+	for zone := range s.DeviceTempC {
+		if _, ok := analyzer.DefaultThresholds(zone); !ok {
+			zones = append(zones, zone)
+		}
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// formatDeviceTemps lists the device zone temperatures, sorted by zone.
+func formatDeviceTemps(temps map[string]float64) string {
+	zones := make([]string, 0, len(temps))
+	for zone := range temps {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	var parts []string
+	for _, zone := range zones {
+		parts = append(parts, fmt.Sprintf("%s %.1f°C", zone, temps[zone]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rawSuffix shows the unfiltered reading when smoothing changed the value the analysis used.
+func rawSuffix(s *sensors.Snapshot, name string, filtered float64) string {
+	raw, ok := s.Raw[name]
+	if !ok || math.Abs(raw-filtered) < 0.05 {
+		return ""
+	}
+	return fmt.Sprintf(" (raw %.1f)", raw)
+}
+
+// formatPower shows package power with the core/uncore split and memory power when available.
+func formatPower(s *sensors.Snapshot) string {
+	out := fmt.Sprintf("%.1f W", s.PowerW)
+	if s.PowerCoreW > 0 || s.PowerUncoreW > 0 {
+		out += fmt.Sprintf(" (core %.1f W, uncore %.1f W)", s.PowerCoreW, s.PowerUncoreW)
+	}
+	if s.PowerDRAMW > 0 {
+		out += fmt.Sprintf(", DRAM %.1f W", s.PowerDRAMW)
+	}
+	return out
+}
+
+// formatPowerSource shows AC or battery, with the battery's charge and temperature.
+func formatPowerSource(s *sensors.Snapshot) string {
+	out := s.PowerSource
+	if s.HasBattery {
+		out += fmt.Sprintf(", battery %.0f%%", s.BatteryPercent)
+		if s.BatteryTempC != 0 {
+			out += fmt.Sprintf(" at %.1f°C", s.BatteryTempC)
+		}
+	}
+	return out
+}
+
+// formatFans lists every fan's speed, with its duty cycle when known.
+func formatFans(fans []sensors.Fan) string {
+	var parts []string
+	for _, f := range fans {
+		part := fmt.Sprintf("%s %.0f RPM", f.Name, f.RPM)
+		if f.HasDuty {
+			part += fmt.Sprintf(" (%.0f%%)", f.DutyPercent)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
 }
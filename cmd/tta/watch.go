@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -10,6 +12,7 @@ import (
 
 	"thermal-throttling-analyzer/internal/analyzer"
 	"thermal-throttling-analyzer/internal/events"
+	"thermal-throttling-analyzer/internal/scenario"
 	"thermal-throttling-analyzer/internal/sensors"
 
 	"github.com/spf13/cobra"
@@ -19,118 +22,352 @@ var watchCmd = &cobra.Command{
 	Use:   "watch",
 	Short: "Tell me when things go bad",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Monitoring thermal state... (Press Ctrl+C to stop)")
-
 		demoMode, _ := cmd.Flags().GetBool("demo")
 		if demoMode {
-			fmt.Println("DEMO MODE ACTIVE: Simulating thermal throttling")
+			watchScenario = "demo"
+		}
+		if watchScenario != "" && replayTrace != "" {
+			fmt.Println("--scenario and --replay-trace cannot be used together")
+			return
+		}
+		speed, err := sensors.ParseReplaySpeed(watchSpeed)
+		if err != nil {
+			fmt.Println(err)
+			return
 		}
 
 		sm := analyzer.NewStateMachine()
+		zones := analyzer.NewDeviceZones()
+		fans := analyzer.NewFanMonitor()
+		pipeline := newPipeline()
 		logger, err := events.NewLogger()
 		if err != nil {
 			fmt.Printf("Error initializing logger: %v\n", err)
 			return
 		}
 
-		// Channel for clean exit
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		// External process for animation
+		var fireCmd *exec.Cmd
 
-		ticker := time.NewTicker(analyzer.SampleInterval) // 2s
-		defer ticker.Stop()
+		var source snapshotSource
+		var player *sensors.TracePlayer
+		var sc *scenario.Scenario
+		switch {
+		case watchScenario != "":
+			// Synthetic data run through the real state machine, labelled as
+			// mocked in the log and timed from now.
+			sc, err = scenario.Resolve(watchScenario)
+			if err == nil {
+				pipeline, err = buildPipeline(sc)
+			}
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			player = sensors.NewTracePlayer(sc.Generate(), speed)
+			player.StartAt = time.Now()
+			player.Loop = demoMode
+			if demoMode {
+				fmt.Println("DEMO MODE ACTIVE: Simulating thermal throttling")
+			} else {
+				fmt.Printf("Running scenario %s (speed %s)\n", sc.Name, watchSpeed)
+			}
+		case replayTrace != "":
+			trace, err := loadReplayTrace()
+			if err != nil {
+				fmt.Printf("Error loading trace: %v\n", err)
+				return
+			}
+			player = sensors.NewTracePlayer(trace, speed)
+			fmt.Printf("Replaying %d snapshots from %s (speed %s)\n", len(trace), replayTrace, watchSpeed)
+		default:
+			source = newLiveSource(func(elapsed time.Duration, missed int) {
+				reportOverrun(logger, fireCmd == nil, elapsed, missed)
+			})
+		}
+		if player != nil {
+			if speed.Step {
+				player.Steps = stepKeys()
+				fmt.Println("Press Enter to advance one snapshot.")
+			}
+			source = player
+		}
 
-		var lastState analyzer.State
-		demoCounter := 0
+		var recorder *sensors.TraceWriter
+		if watchRecordTrace != "" {
+			recorder, err = sensors.NewTraceWriter(watchRecordTrace)
+			if err != nil {
+				fmt.Printf("Error creating trace file: %v\n", err)
+				return
+			}
+			defer recorder.Close()
+		}
 
-		// External process for animation
-		var fireCmd *exec.Cmd
+		dataSource := "sensors"
+		switch {
+		case sc != nil:
+			dataSource = "scenario " + sc.Name
+		case replayTrace != "":
+			dataSource = "trace " + replayTrace
+		}
+		reportSessionStart(logger, sessionMetadata(dataSource))
+
+		fmt.Println("Monitoring thermal state... (Press Ctrl+C to stop)")
+
+		// Cancelled on Ctrl+C for a clean exit
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		var lastState analyzer.State
+		var lastSource string // Power source of the previous snapshot
+		var states []string   // State sequence, checked against a scenario's expectation
+		lastZoneStates := map[string]analyzer.State{}
+		ignoredSeen := 0 // Zones without thresholds reported so far
 
 		for {
-			select {
-			case <-sigChan:
-				fmt.Println("\nStopping monitor.")
+			snap, err := source.Next(ctx)
+			if err != nil {
+				if err == io.EOF {
+					fmt.Println("\nReplay finished.")
+					if sc != nil && len(sc.Expect) > 0 {
+						if err := sc.CheckStates(states); err != nil {
+							fmt.Println(err)
+						} else {
+							fmt.Printf("Scenario %s went through the expected states.\n", sc.Name)
+						}
+					}
+				} else {
+					fmt.Println("\nStopping monitor.")
+				}
 				if fireCmd != nil && fireCmd.Process != nil {
 					_ = fireCmd.Process.Kill()
 				}
 				return
-			case <-ticker.C:
-				snap := sensors.CollectSnapshot()
-				res := sm.UpdateWithHistory(snap)
-
-				if demoMode {
-					demoCounter++
-					// Toggle state every 10 seconds (5 samples)
-					cycle := (demoCounter / 5) % 2
-					if cycle == 1 {
-						res.State = analyzer.StateThrottling
-						res.Reason = "Simulated Demo Throttling"
-						snap.TempC = 98.5
-					} else {
-						res.State = analyzer.StateNormal
-						res.Reason = "Simulated Normal"
-						snap.TempC = 45.0
-					}
+			}
+
+			if recorder != nil {
+				if err := recorder.Write(snap); err != nil {
+					fmt.Printf("Error recording trace: %v\n", err)
+				}
+			}
+			// The trace keeps what the sensors said; analysis only sees sane, smoothed values.
+			pipeline.Process(snap)
+			res := sm.UpdateWithHistory(snap)
+			if snap.HasSignal(sensors.SignalPowerSource) {
+				if lastSource != "" && snap.PowerSource != lastSource {
+					reportPowerSourceChange(logger, fireCmd == nil, snap, lastSource)
+				}
+				lastSource = snap.PowerSource
+			}
+			for _, alert := range fans.Update(snap, res.State) {
+				reportFanAlert(logger, fireCmd == nil, snap, alert)
+			}
+			for _, zr := range zones.UpdateWithHistory(snap) {
+				if zr.State != lastZoneStates[zr.Zone] {
+					reportZoneState(logger, fireCmd == nil, snap, zr)
+					lastZoneStates[zr.Zone] = zr.State
+				}
+			}
+			for ; ignoredSeen < len(zones.Ignored); ignoredSeen++ {
+				if fireCmd == nil {
+					fmt.Printf("[%s] %s: not analyzed, no thresholds for this zone\n", snap.Timestamp.Format("15:04"), zones.Ignored[ignoredSeen])
 				}
+			}
 
-				if res.State == analyzer.StateThrottling {
-					if fireCmd == nil {
-						// Start fire animation
-						// Assume gh-yule-log is in PATH or GOPATH/bin
-						// We'll try to run it directly
-						fireCmd = exec.Command("gh-yule-log")
-						fireCmd.Stdout = os.Stdout
-						fireCmd.Stdin = os.Stdin
-						fireCmd.Stderr = os.Stderr
-
-						if err := fireCmd.Start(); err != nil {
-							fmt.Printf("\nError starting fire animation: %v\n", err)
-							fireCmd = nil
-						}
-					}
-				} else {
-					if fireCmd != nil {
-						if fireCmd.Process != nil {
-							_ = fireCmd.Process.Signal(os.Interrupt)
-							// Allow brief time to cleanup, otherwise kill
-							go func(p *os.Process) {
-								time.Sleep(200 * time.Millisecond)
-								_ = p.Kill()
-							}(fireCmd.Process)
-						}
-						_ = fireCmd.Wait()
+			if res.State == analyzer.StateThrottling {
+				if fireCmd == nil {
+					// Start fire animation
+					// Assume gh-yule-log is in PATH or GOPATH/bin
+					// We'll try to run it directly
+					fireCmd = exec.Command("gh-yule-log")
+					fireCmd.Stdout = os.Stdout
+					fireCmd.Stdin = os.Stdin
+					fireCmd.Stderr = os.Stderr
+
+					if err := fireCmd.Start(); err != nil {
+						fmt.Printf("\nError starting fire animation: %v\n", err)
 						fireCmd = nil
-						// Clear screen/reset cursor
-						fmt.Print("\033[2J\033[H")
-						// Reprint monitoring status as clearing screen wipes it
-						fmt.Println("Monitoring thermal state... (Press Ctrl+C to stop)")
 					}
 				}
-
-				// Print only on state change or significant event
-				if res.State != lastState {
-					timestamp := time.Now().Format("15:04")
-					// If fire is running, this might get messy, but standard output is shared
-					if fireCmd == nil {
-						fmt.Printf("[%s] %s detected (temp %.0f°C)\n", timestamp, res.State, snap.TempC)
+			} else {
+				if fireCmd != nil {
+					if fireCmd.Process != nil {
+						_ = fireCmd.Process.Signal(os.Interrupt)
+						// Allow brief time to cleanup, otherwise kill
+						go func(p *os.Process) {
+							time.Sleep(200 * time.Millisecond)
+							_ = p.Kill()
+						}(fireCmd.Process)
 					}
+					_ = fireCmd.Wait()
+					fireCmd = nil
+					// Clear screen/reset cursor
+					fmt.Print("\033[2J\033[H")
+					// Reprint monitoring status as clearing screen wipes it
+					fmt.Println("Monitoring thermal state... (Press Ctrl+C to stop)")
+				}
+			}
 
-					// Log event
-					_ = logger.LogEvent(events.Event{
-						Timestamp: time.Now(),
-						Type:      string(res.State),
-						State:     string(res.State),
-						Details:   res.Reason,
-					})
-
-					lastState = res.State
+			// Print only on state change or significant event
+			if res.State != lastState {
+				// Replayed snapshots keep their recorded time.
+				timestamp := snap.Timestamp.Format("15:04")
+				// If fire is running, this might get messy, but standard output is shared
+				if fireCmd == nil {
+					fmt.Printf("[%s] %s detected (temp %s)\n", timestamp, res.State, formatTemp(snap))
 				}
+
+				// Log event
+				_ = logger.LogEvent(events.Event{
+					Timestamp: snap.Timestamp,
+					Type:      string(res.State),
+					State:     string(res.State),
+					Zone:      analyzer.ZoneCPU,
+					Details:   res.Reason,
+					Signals:   snap.SourceSummary(),
+					PowerW:    snap.PowerW,
+					Causes:    causeNames(res.Causes),
+
+					CPUPressure: snap.PSISomeAvg10,
+					Impact:      string(res.Impact),
+					Rejected:    snap.Rejected,
+					Raw:         snap.Raw,
+
+					PowerSource:    powerSource(snap),
+					BatteryPercent: snap.BatteryPercent,
+				})
+
+				states = append(states, string(res.State))
+				lastState = res.State
 			}
 		}
 	},
 }
 
+var (
+	watchScenario    string
+	watchSpeed       string
+	watchRecordTrace string
+)
+
+func causeNames(causes []analyzer.Cause) []string {
+	var names []string
+	for _, c := range causes {
+		names = append(names, string(c))
+	}
+	return names
+}
+
+// reportOverrun warns that a tick took longer than the sample interval,
+// so samples are no longer evenly spaced.
+func reportOverrun(logger *events.Logger, printIt bool, elapsed time.Duration, missed int) {
+	details := fmt.Sprintf("Tick took %s (interval %s), %d sample(s) skipped",
+		elapsed.Round(time.Millisecond), analyzer.SampleInterval, missed)
+	if printIt {
+		fmt.Printf("[%s] Warning: %s\n", time.Now().Format("15:04"), details)
+	}
+	_ = logger.LogEvent(events.Event{
+		Timestamp: time.Now(),
+		Type:      "TICK_OVERRUN",
+		Details:   details,
+	})
+}
+
+// reportSessionStart logs how this run reads its data, so the events after it
+// can be traced back to the sensors they came from.
+func reportSessionStart(logger *events.Logger, meta map[string]string) {
+	details := fmt.Sprintf("Reading %s", meta["source"])
+	if meta["source"] == "sensors" {
+		details = fmt.Sprintf("Reading sensors through backend %s, CPU temperature: %s", meta["backend"], meta["cpu_temp"])
+	}
+	_ = logger.LogEvent(events.Event{
+		Timestamp: time.Now(),
+		Type:      "SESSION_START",
+		Details:   details,
+		Session:   meta,
+	})
+}
+
+// reportZoneState prints and logs a state change of a device zone.
+// The event carries the zone, so it is not taken for a CPU state change.
+func reportZoneState(logger *events.Logger, printIt bool, snap *sensors.Snapshot, res analyzer.AnalysisResult) {
+	if printIt {
+		fmt.Printf("[%s] %s: %s (%s)\n", snap.Timestamp.Format("15:04"), res.Zone, res.State, res.Reason)
+	}
+	_ = logger.LogEvent(events.Event{
+		Timestamp: snap.Timestamp,
+		Type:      string(res.State),
+		State:     string(res.State),
+		Zone:      res.Zone,
+		Details:   res.Reason,
+		Signals:   snap.SourceSummary(),
+		Causes:    causeNames(res.Causes),
+	})
+}
+
+// reportFanAlert prints and logs a cooling hardware problem.
+// The event has no state: it must not be counted as a state change.
+func reportFanAlert(logger *events.Logger, printIt bool, snap *sensors.Snapshot, alert analyzer.FanAlert) {
+	if printIt {
+		fmt.Printf("[%s] Cooling: %s\n", snap.Timestamp.Format("15:04"), alert.Details)
+	}
+	_ = logger.LogEvent(events.Event{
+		Timestamp: snap.Timestamp,
+		Type:      string(alert.Kind),
+		Details:   alert.Details,
+		Signals:   snap.SourceSummary(),
+		Fan:       alert.Fan,
+		FanRPM:    alert.RPM,
+	})
+}
+
+// reportPowerSourceChange prints and logs a switch between AC and battery.
+func reportPowerSourceChange(logger *events.Logger, printIt bool, snap *sensors.Snapshot, from string) {
+	details := fmt.Sprintf("Switched from %s to %s", from, snap.PowerSource)
+	if snap.HasBattery {
+		details = fmt.Sprintf("%s (battery %.0f%%)", details, snap.BatteryPercent)
+	}
+	if printIt {
+		fmt.Printf("[%s] %s\n", snap.Timestamp.Format("15:04"), details)
+	}
+	_ = logger.LogEvent(events.Event{
+		Timestamp:      snap.Timestamp,
+		Type:           "POWER_SOURCE_CHANGE",
+		Details:        details,
+		Signals:        snap.SourceSummary(),
+		PowerSource:    snap.PowerSource,
+		BatteryPercent: snap.BatteryPercent,
+	})
+}
+
+// powerSource is the snapshot's power source, or "" when it is unknown.
+func powerSource(s *sensors.Snapshot) string {
+	if !s.HasSignal(sensors.SignalPowerSource) {
+		return ""
+	}
+	return s.PowerSource
+}
+
+// formatTemp renders the temperature with its provenance when it is not a real reading.
+func formatTemp(s *sensors.Snapshot) string {
+	src := s.Source(sensors.SignalTemp)
+	switch src.Provenance {
+	case sensors.ProvenanceMissing:
+		return "unavailable"
+	case sensors.ProvenanceRejected:
+		return fmt.Sprintf("%.0f°C rejected: %s", s.TempC, s.Rejected[sensors.SignalTemp])
+	case sensors.ProvenanceMeasured:
+		return fmt.Sprintf("%.0f°C", s.TempC)
+	default:
+		return fmt.Sprintf("%.0f°C, %s", s.TempC, src.Provenance)
+	}
+}
+
 func init() {
 	watchCmd.Flags().Bool("demo", false, "Simulate thermal throttling state")
+	_ = watchCmd.Flags().MarkDeprecated("demo", "use --scenario demo")
+	watchCmd.Flags().StringVar(&watchScenario, "scenario", "", "Run a synthetic scenario (built-in name or scenario file) instead of reading the sensors")
+	watchCmd.Flags().StringVar(&watchSpeed, "speed", "original", "Replay speed for --replay-trace and --scenario: original, step, max or a factor like 10x")
+	watchCmd.Flags().StringVar(&watchRecordTrace, "record-trace", "", "Record every snapshot to this JSONL trace file")
 	rootCmd.AddCommand(watchCmd)
 }
@@ -0,0 +1,56 @@
+package advice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"thermal-throttling-analyzer/internal/events"
+)
+
+// CoolingSummary counts the fan problems recorded in the log.
+type CoolingSummary struct {
+	Stalls      int      // FAN_STALL: the CPU heated up but a fan did not speed up, or stopped
+	Maxed       int      // FAN_MAXED: fans at full speed, CPU throttling anyway
+	StalledFans []string // Fans named in FAN_STALL events, sorted
+}
+
+// SummarizeCooling collects the fan events.
+func SummarizeCooling(eventsList []events.Event) CoolingSummary {
+	var sum CoolingSummary
+	seen := map[string]bool{}
+	for _, e := range eventsList {
+		switch e.Type {
+		case "FAN_STALL":
+			sum.Stalls++
+			if e.Fan != "" && !seen[e.Fan] {
+				seen[e.Fan] = true
+				sum.StalledFans = append(sum.StalledFans, e.Fan)
+			}
+		case "FAN_MAXED":
+			sum.Maxed++
+		}
+	}
+	sort.Strings(sum.StalledFans)
+	return sum
+}
+
+// Findings describes each cooling hardware problem with what to check.
+func (s CoolingSummary) Findings() []string {
+	var findings []string
+	if s.Stalls > 0 {
+		fans := "a fan"
+		if len(s.StalledFans) > 0 {
+			fans = strings.Join(s.StalledFans, ", ")
+		}
+		findings = append(findings, fmt.Sprintf(
+			"Cooling hardware: %s did not speed up, or stopped, as the CPU heated (%d event(s)). Check that the fan spins freely, is plugged in, and is set to follow the CPU temperature in the BIOS.",
+			fans, s.Stalls))
+	}
+	if s.Maxed > 0 {
+		findings = append(findings, fmt.Sprintf(
+			"Cooling hardware: the fans ran at full speed and the CPU still throttled (%d event(s)). The heatsink is likely clogged with dust, or its thermal paste has dried out.",
+			s.Maxed))
+	}
+	return findings
+}
@@ -17,7 +17,7 @@ func GenerateDoctorReport(eventsList []events.Event) string {
 	last24h := time.Now().Add(-24 * time.Hour)
 	
 	for _, e := range eventsList {
-		if e.State == "THROTTLING" {
+		if e.State == "THROTTLING" && e.CPUZone() {
 			throttleCount++
 			if e.Timestamp.After(last24h) {
 				recentThrottleCount++
@@ -27,12 +27,50 @@ func GenerateDoctorReport(eventsList []events.Event) string {
 	
 	sb.WriteString("System Health Report:\n")
 	
+	// A failing fan is worth reporting before it has caused any throttling.
+	cooling := SummarizeCooling(eventsList).Findings()
+	// So is a drive or battery that throttles itself, whatever the CPU does.
+	zones := SummarizeZones(eventsList).Findings()
+	
 	if throttleCount == 0 {
-		sb.WriteString("• No throttling events detected in logs. System appears healthy.\n")
+		if len(cooling) == 0 && len(zones) == 0 {
+			sb.WriteString("• No throttling events detected in logs. System appears healthy.\n")
+			return sb.String()
+		}
+		sb.WriteString("• No CPU throttling events detected in logs yet.\n")
+		for _, finding := range append(cooling, zones...) {
+			sb.WriteString(fmt.Sprintf("• %s\n", finding))
+		}
 		return sb.String()
 	}
 	
-	sb.WriteString(fmt.Sprintf("• %d throttling events detected (%d in last 24h).\n\n", throttleCount, recentThrottleCount))
+	sb.WriteString(fmt.Sprintf("• %d throttling events detected (%d in last 24h).\n", throttleCount, recentThrottleCount))
+	
+	// 2. Was anyone actually waiting? Throttling an idle CPU costs nothing.
+	impact := SummarizeImpact(eventsList)
+	if impact.Known() {
+		sb.WriteString(fmt.Sprintf("• User-visible impact: %s.\n", impact))
+		if impact.High == 0 && impact.Low == 0 {
+			sb.WriteString("• No task was waiting for the CPU while it throttled; this is unlikely to have slowed you down.\n")
+		}
+	}
+	
+	// 3. AC or battery? Firmware often drops to a slower profile when unplugged.
+	power := SummarizePowerSource(eventsList)
+	if power.Known() {
+		sb.WriteString(fmt.Sprintf("• Throttling by power source: %s.\n", power))
+	}
+	
+	// 4. Fans that did not respond, or could not keep up.
+	for _, finding := range cooling {
+		sb.WriteString(fmt.Sprintf("• %s\n", finding))
+	}
+	
+	// 5. Drives, SoCs and batteries that throttled themselves.
+	for _, finding := range zones {
+		sb.WriteString(fmt.Sprintf("• %s\n", finding))
+	}
+	sb.WriteString("\n")
 	
 	sb.WriteString("Suggestions (Risk Reduction):\n")
 	
@@ -40,6 +78,14 @@ func GenerateDoctorReport(eventsList []events.Event) string {
 	sb.WriteString("• Ensure air vents are not obstructed.\n")
 	sb.WriteString("• Avoid soft surfaces (blankets, laps) which block airflow.\n")
 	
+	if power.MostlyOnBattery() {
+		sb.WriteString("• Most throttling happens on battery: the firmware likely switches to a power-saving profile when unplugged. Check the power mode used on battery, or plug in for heavy work.\n")
+	}
+	
+	if impact.High > 0 {
+		sb.WriteString("• Throttling stalled running work; schedule heavy jobs (builds, renders) when the system is cool, or improve cooling.\n")
+	}
+	
 	if recentThrottleCount > 5 {
 		sb.WriteString("• Consider using 'Balanced' power profile instead of 'High Performance'.\n")
 		sb.WriteString("• High ambient temperatures may be contributing.\n")
@@ -0,0 +1,56 @@
+package advice
+
+import (
+	"fmt"
+
+	"thermal-throttling-analyzer/internal/events"
+)
+
+// ImpactSummary counts throttling events by how much they were felt, based on
+// the CPU pressure recorded with each event.
+type ImpactSummary struct {
+	None        int // Throttled, but nothing was waiting for the CPU
+	Low         int
+	High        int
+	Unknown     int     // Logged without pressure data
+	AvgPressure float64 // Mean PSI "some" avg10 over events with pressure data
+}
+
+// SummarizeImpact looks at throttling events only; other states have no impact to rate.
+func SummarizeImpact(eventsList []events.Event) ImpactSummary {
+	var sum ImpactSummary
+	var pressureTotal float64
+	for _, e := range eventsList {
+		if e.State != "THROTTLING" || !e.CPUZone() {
+			continue
+		}
+		switch e.Impact {
+		case "none":
+			sum.None++
+		case "low":
+			sum.Low++
+		case "high":
+			sum.High++
+		default:
+			sum.Unknown++
+			continue
+		}
+		pressureTotal += e.CPUPressure
+	}
+	if known := sum.None + sum.Low + sum.High; known > 0 {
+		sum.AvgPressure = pressureTotal / float64(known)
+	}
+	return sum
+}
+
+// Known reports whether any throttling event carried pressure data.
+func (s ImpactSummary) Known() bool {
+	return s.None+s.Low+s.High > 0
+}
+
+// String renders the summary as one line, e.g.
+// "2 stalled work, 1 noticeable, 4 unnoticed (avg CPU pressure 12%)".
+func (s ImpactSummary) String() string {
+	return fmt.Sprintf("%d stalled work, %d noticeable, %d unnoticed (avg CPU pressure %.0f%%)",
+		s.High, s.Low, s.None, s.AvgPressure)
+}
@@ -0,0 +1,57 @@
+package advice
+
+import (
+	"fmt"
+
+	"thermal-throttling-analyzer/internal/events"
+)
+
+// PowerSourceSummary splits throttling events by what the machine ran on.
+type PowerSourceSummary struct {
+	AC       int
+	Battery  int
+	Unknown  int // Logged without a power source (desktops, older logs)
+	Switches int // POWER_SOURCE_CHANGE events
+}
+
+// SummarizePowerSource counts throttling events on AC and on battery.
+func SummarizePowerSource(eventsList []events.Event) PowerSourceSummary {
+	var sum PowerSourceSummary
+	for _, e := range eventsList {
+		if e.Type == "POWER_SOURCE_CHANGE" {
+			sum.Switches++
+			continue
+		}
+		if e.State != "THROTTLING" || !e.CPUZone() {
+			continue
+		}
+		switch e.PowerSource {
+		case "ac":
+			sum.AC++
+		case "battery":
+			sum.Battery++
+		default:
+			sum.Unknown++
+		}
+	}
+	return sum
+}
+
+// Known reports whether any throttling event recorded its power source.
+func (s PowerSourceSummary) Known() bool {
+	return s.AC+s.Battery > 0
+}
+
+// MostlyOnBattery reports whether the machine throttled clearly more on battery.
+func (s PowerSourceSummary) MostlyOnBattery() bool {
+	return s.Battery >= 2 && s.Battery > 2*s.AC
+}
+
+// String renders the summary as one line, e.g. "5 on battery, 1 on AC".
+func (s PowerSourceSummary) String() string {
+	out := fmt.Sprintf("%d on battery, %d on AC", s.Battery, s.AC)
+	if s.Unknown > 0 {
+		out += fmt.Sprintf(", %d unknown", s.Unknown)
+	}
+	return out
+}
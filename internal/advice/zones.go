@@ -0,0 +1,85 @@
+package advice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"thermal-throttling-analyzer/internal/events"
+)
+
+// ZoneCounts counts the state changes of one device zone.
+type ZoneCounts struct {
+	Throttling int // The zone reached its throttle point
+	HeatStress int // The zone ran hot
+}
+
+// ZoneSummary counts the state changes of the device zones (NVMe, SoC,
+// battery), keyed by zone. CPU events are left to the other summaries.
+type ZoneSummary map[string]ZoneCounts
+
+// SummarizeZones counts the device zone events.
+func SummarizeZones(eventsList []events.Event) ZoneSummary {
+	sum := ZoneSummary{}
+	for _, e := range eventsList {
+		if e.CPUZone() {
+			continue
+		}
+		c := sum[e.Zone]
+		switch e.State {
+		case "THROTTLING":
+			c.Throttling++
+		case "HEAT_STRESS":
+			c.HeatStress++
+		default:
+			continue
+		}
+		sum[e.Zone] = c
+	}
+	return sum
+}
+
+// Zones returns the zones with events, sorted.
+func (s ZoneSummary) Zones() []string {
+	zones := make([]string, 0, len(s))
+	for zone := range s {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// String renders the summary as one line, e.g. "nvme 2 throttling, 3 heat stress".
+func (s ZoneSummary) String() string {
+	var parts []string
+	for _, zone := range s.Zones() {
+		c := s[zone]
+		parts = append(parts, fmt.Sprintf("%s %d throttling, %d heat stress", zone, c.Throttling, c.HeatStress))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Findings describes each device zone that throttled, with what to check.
+// Zones that only ran hot are not worth a finding.
+func (s ZoneSummary) Findings() []string {
+	var findings []string
+	for _, zone := range s.Zones() {
+		c := s[zone]
+		if c.Throttling == 0 {
+			continue
+		}
+		var advice string
+		switch zone {
+		case "nvme":
+			advice = "The drive slows its reads and writes down when this hot. Fit a heatsink on the drive, or make sure the case airflow reaches it."
+		case "battery":
+			advice = "Batteries limit charging and power draw when hot, and age faster. Avoid charging under heavy load, and keep the machine off soft surfaces."
+		case "soc":
+			advice = "The whole chip slows down when this hot. Check the board's heatsink and that the enclosure is ventilated."
+		default:
+			advice = "Check the cooling of this part."
+		}
+		findings = append(findings, fmt.Sprintf("%s zone: reached its throttle point (%d event(s)). %s", zone, c.Throttling, advice))
+	}
+	return findings
+}
@@ -11,16 +11,33 @@ const (
 )
 
 // CalculateConfidence determines the confidence of the analysis based on snapshot quality.
+// Only real readings count: mocked data never earns more than Low, and
+// estimated or stale signals cap the result at Medium.
 func CalculateConfidence(s *sensors.Snapshot) ConfidenceLevel {
-	// 1. Count valid signals
-	validCount := len(s.ValidSignals)
+	// 1. Count measured core signals, noting anything less trustworthy.
+	// Optional extras (power, ...) don't make the thermal diagnosis more certain.
+	measuredCount := 0
+	estimated := false
+	for _, name := range sensors.CoreSignals {
+		switch s.Source(name).Provenance {
+		case sensors.ProvenanceMocked:
+			return ConfidenceLow
+		case sensors.ProvenanceEstimated, sensors.ProvenanceStale:
+			estimated = true
+		case sensors.ProvenanceMeasured:
+			measuredCount++
+		}
+	}
 	
 	// 2. Logic
-	if validCount >= 3 {
-		// All sensors (Temp, Freq, Load) available
+	// A thermal diagnosis without a measured temperature is never certain,
+	// however many other signals were read.
+	tempMeasured := s.Source(sensors.SignalTemp).Provenance == sensors.ProvenanceMeasured
+	if tempMeasured && measuredCount >= 3 && !estimated {
+		// Temperature and the other core signals all measured
 		return ConfidenceHigh
 	}
-	if validCount == 2 {
+	if measuredCount >= 2 {
 		return ConfidenceMedium
 	}
 	
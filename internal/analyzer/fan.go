@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// FanAlertKind names a cooling hardware problem. The values are used as event types.
+type FanAlertKind string
+
+const (
+	// FanStalled: the CPU heated up while a fan stayed at the same speed or stopped.
+	FanStalled FanAlertKind = "FAN_STALL"
+	// FanMaxed: a fan ran at full speed and the CPU throttled anyway.
+	FanMaxed FanAlertKind = "FAN_MAXED"
+)
+
+// FanAlert is one cooling hardware problem spotted by a FanMonitor.
+type FanAlert struct {
+	Kind    FanAlertKind
+	Fan     string
+	RPM     float64
+	Details string
+}
+
+// fanSample is the temperature and fan speeds at one point in time.
+type fanSample struct {
+	at   time.Time
+	temp float64
+	rpm  map[string]float64
+}
+
+// FanMonitor watches fans against the temperature over successive snapshots.
+// An alert is raised once when its condition starts and again only after it
+// has cleared, so a stuck fan does not flood the log.
+type FanMonitor struct {
+	samples []fanSample     // Within FanStallWindow, oldest first
+	spun    map[string]bool // Fans seen spinning; a header that never did is unconnected
+	active  map[string]bool // Conditions currently raised, by kind and fan
+}
+
+// NewFanMonitor returns a monitor with no history.
+func NewFanMonitor() *FanMonitor {
+	return &FanMonitor{spun: map[string]bool{}, active: map[string]bool{}}
+}
+
+// Update records s and returns the alerts that started with it. state is the
+// analysis result for s.
+func (m *FanMonitor) Update(s *sensors.Snapshot, state State) []FanAlert {
+	if !s.HasSignal(sensors.SignalFans) {
+		return nil
+	}
+	now := s.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	rpm := make(map[string]float64, len(s.Fans))
+	for _, f := range s.Fans {
+		rpm[f.Name] = f.RPM
+		if f.RPM > 0 {
+			m.spun[f.Name] = true
+		}
+	}
+
+	raised := map[string]FanAlert{}
+	if s.HasSignal(sensors.SignalTemp) {
+		m.samples = append(m.samples, fanSample{at: now, temp: s.TempC, rpm: rpm})
+		for len(m.samples) > 1 && now.Sub(m.samples[1].at) >= FanStallWindow {
+			m.samples = m.samples[1:]
+		}
+		first := m.samples[0]
+		elapsed := now.Sub(first.at)
+		rise := s.TempC - first.temp
+		// Fan curves react over a few seconds, so a rise is only judged over
+		// at least half the window.
+		if elapsed >= FanStallWindow/2 && rise >= FanStallTempRiseC && s.TempC >= FanStallMinTempC {
+			for _, f := range s.Fans {
+				if f.HasDuty && f.DutyPercent >= FanFullDutyPercent {
+					continue // Already flat out; see FanMaxed
+				}
+				before, ok := first.rpm[f.Name]
+				if !ok {
+					continue
+				}
+				var details string
+				switch {
+				case f.RPM == 0 && m.spun[f.Name]:
+					details = fmt.Sprintf("Fan %s stopped while the CPU heated from %.0f°C to %.0f°C in %s",
+						f.Name, first.temp, s.TempC, elapsed.Round(time.Second))
+				case before > 0 && math.Abs(f.RPM-before)/before*100 < FanFlatPercent:
+					details = fmt.Sprintf("Fan %s stayed at %.0f RPM while the CPU heated from %.0f°C to %.0f°C in %s",
+						f.Name, f.RPM, first.temp, s.TempC, elapsed.Round(time.Second))
+				default:
+					continue
+				}
+				raised[string(FanStalled)+"/"+f.Name] = FanAlert{Kind: FanStalled, Fan: f.Name, RPM: f.RPM, Details: details}
+			}
+		}
+	}
+
+	if state == StateThrottling {
+		for _, f := range s.Fans {
+			if !f.HasDuty || f.DutyPercent < FanFullDutyPercent {
+				continue
+			}
+			details := fmt.Sprintf("Fan %s at full speed (%.0f RPM) yet the CPU is throttling", f.Name, f.RPM)
+			if s.HasSignal(sensors.SignalTemp) {
+				details = fmt.Sprintf("%s at %.0f°C", details, s.TempC)
+			}
+			raised[string(FanMaxed)+"/"+f.Name] = FanAlert{Kind: FanMaxed, Fan: f.Name, RPM: f.RPM, Details: details}
+		}
+	}
+
+	// Report newly raised conditions in fan order; forget the ones that cleared.
+	var alerts []FanAlert
+	for _, kind := range []FanAlertKind{FanStalled, FanMaxed} {
+		for _, f := range s.Fans {
+			key := string(kind) + "/" + f.Name
+			if a, ok := raised[key]; ok && !m.active[key] {
+				alerts = append(alerts, a)
+			}
+		}
+	}
+	for key := range m.active {
+		if _, ok := raised[key]; !ok {
+			delete(m.active, key)
+		}
+	}
+	for key := range raised {
+		m.active[key] = true
+	}
+	return alerts
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// Impact estimates how much a slowdown was felt, judged by whether tasks
+// were actually waiting for a CPU at the time.
+type Impact string
+
+const (
+	ImpactUnknown Impact = ""     // No pressure data
+	ImpactNone    Impact = "none" // Throttled, but nobody was waiting
+	ImpactLow     Impact = "low"  // Some waiting, unlikely to be noticed
+	ImpactHigh    Impact = "high" // Runnable work stalled for a large share of the time
+)
+
+// EstimateImpact rates the user-visible impact from CPU pressure stall
+// information. Without PSI the impact is unknown.
+func EstimateImpact(s *sensors.Snapshot) Impact {
+	if !s.HasSignal(sensors.SignalPressure) {
+		return ImpactUnknown
+	}
+	switch {
+	case s.PSISomeAvg10 >= PressureSeverePercent:
+		return ImpactHigh
+	case s.PSISomeAvg10 >= PressureNoticeablePercent:
+		return ImpactLow
+	default:
+		return ImpactNone
+	}
+}
+
+// describeImpact renders the impact for a reason string.
+func describeImpact(impact Impact, s *sensors.Snapshot) string {
+	switch impact {
+	case ImpactNone:
+		return fmt.Sprintf("no tasks waiting for CPU (pressure %.1f%%)", s.PSISomeAvg10)
+	case ImpactLow:
+		return fmt.Sprintf("tasks waited for CPU %.0f%% of the time", s.PSISomeAvg10)
+	case ImpactHigh:
+		return fmt.Sprintf("work stalled, tasks waited for CPU %.0f%% of the time", s.PSISomeAvg10)
+	}
+	return ""
+}
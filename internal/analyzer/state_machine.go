@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"fmt"
+	"strings"
 	"thermal-throttling-analyzer/internal/sensors"
 	"time"
 )
@@ -15,15 +16,29 @@ const (
 	StateRecovery   State = "RECOVERY"
 )
 
+// Cause names what held the CPU back. Several causes can apply at once.
+type Cause string
+
+const (
+	CauseThermal Cause = "thermal"
+	CauseQuota   Cause = "cpu-quota" // cgroup CFS bandwidth limit, not heat
+)
+
 type AnalysisResult struct {
+	Zone       string // ZoneCPU or a device zone
 	State      State
 	Reason     string
 	Confidence ConfidenceLevel
+	Causes     []Cause
+	Impact     Impact         // How much the throttling was felt; unknown when not throttled
+	Thresholds ZoneThresholds // Temperature limits the zone was judged against
 	Snapshot   *sensors.Snapshot
 }
 
 // StateMachine holds the history and current state logic.
 type StateMachine struct {
+	Zone            string // ZoneCPU, or the device zone this machine judges
+	Thresholds      ZoneThresholds
 	CurrentState    State
 	LastTransition  time.Time
 	// Track recent snapshots for duration-based logic
@@ -32,12 +47,36 @@ type StateMachine struct {
 }
 
 func NewStateMachine() *StateMachine {
+	t, _ := DefaultThresholds(ZoneCPU)
+	return NewZoneStateMachine(ZoneCPU, t)
+}
+
+// NewZoneStateMachine returns a state machine for one zone with its own limits.
+func NewZoneStateMachine(zone string, t ZoneThresholds) *StateMachine {
 	return &StateMachine{
+		Zone:           zone,
+		Thresholds:     t,
 		CurrentState:   StateNormal,
 		LastTransition: time.Now(),
 	}
 }
 
+// thresholds returns the machine's limits, or the zone defaults when unset.
+func (sm *StateMachine) thresholds() ZoneThresholds {
+	if sm.Thresholds == (ZoneThresholds{}) {
+		t, _ := DefaultThresholds(sm.zone())
+		return t
+	}
+	return sm.Thresholds
+}
+
+func (sm *StateMachine) zone() string {
+	if sm.Zone == "" {
+		return ZoneCPU
+	}
+	return sm.Zone
+}
+
 // AnalyzeSnapshot processes a single snapshot and returns the analysis.
 // This function is stateless in the sense that it doesn't mutate the machine's state,
 // but returns what the state *is* based on the snapshot.
@@ -46,14 +85,21 @@ func NewStateMachine() *StateMachine {
 // So the StateMachine MUST persist state and track duration.
 
 func (sm *StateMachine) Update(s *sensors.Snapshot) AnalysisResult {
+	if sm.zone() != ZoneCPU {
+		return sm.updateDevice(s)
+	}
+
 	// 1. Calculate base metrics
-	isHighTemp := s.TempC >= TempHighThreshold
-	isCriticalTemp := s.TempC >= TempCriticalThreshold
+	// Missing or rejected signals keep whatever value was read; don't judge on them.
+	t := sm.thresholds()
+	hasTemp := s.HasSignal(sensors.SignalTemp)
+	isHighTemp := hasTemp && s.TempC >= t.HighC
+	isCriticalTemp := hasTemp && s.TempC >= t.CriticalC
 	
 	// Freq drop?
 	// If current freq is significantly lower than base freq
 	freqRatio := 1.0
-	if s.BaseFreqMHz > 0 {
+	if s.BaseFreqMHz > 0 && s.HasSignal(sensors.SignalFreq) && s.HasSignal(sensors.SignalBaseFreq) {
 		freqRatio = float64(s.FreqMHz) / float64(s.BaseFreqMHz)
 	}
 	isFreqDrop := freqRatio <= (1.0 - FreqDropPercentage)
@@ -61,7 +107,8 @@ func (sm *StateMachine) Update(s *sensors.Snapshot) AnalysisResult {
 	// Load?
 	// Throttling usually happens under load.
 	// If load is low, freq drop is normal (idle).
-	isHighLoad := s.LoadPercent > 50.0 // heuristic
+	isHighLoad := s.HasSignal(sensors.SignalLoad) && s.LoadPercent > HighLoadPercent // heuristic
+	isStarved := s.StealPercent >= StealHighPercent
 	
 	// Determine the "Instant" State indicated by THIS snapshot
 	instantState := StateNormal
@@ -102,24 +149,123 @@ func (sm *StateMachine) Update(s *sensors.Snapshot) AnalysisResult {
 		// but simple state machine transition logic handles 'Recovery' state.
 	}
 	
+	// Kernel throttle counters are direct evidence rather than a heuristic: any
+	// increment means the CPU was throttled during this tick, whatever the
+	// temperature readout says, or whether there is one at all.
+	kernelThrottled := s.HasSignal(sensors.SignalThrottle) && s.ThrottleEvents() > 0
+	if kernelThrottled {
+		instantState = StateThrottling
+		reason = fmt.Sprintf("Kernel reported %d thermal throttle event(s), %d ms throttled",
+			s.ThrottleEvents(), s.ThrottleMs())
+		if s.HasSignal(sensors.SignalTemp) {
+			reason = fmt.Sprintf("%s (temp %.1fC)", reason, s.TempC)
+		}
+	}
+	
+	// The thermal status MSRs are the CPU's own account of why it is limited.
+	hwThrottled := s.HasSignal(sensors.SignalThermStatus) && s.HWThrottling
+	if hwThrottled && !kernelThrottled {
+		instantState = StateThrottling
+		reason = "CPU thermal status register reports throttling"
+		if s.HasSignal(sensors.SignalTemp) {
+			reason = fmt.Sprintf("%s (temp %.1fC)", reason, s.TempC)
+		}
+	}
+	if s.HasSignal(sensors.SignalThermStatus) && len(s.HWThrottleReasons) > 0 {
+		reason = fmt.Sprintf("%s; hardware: %s", reason, strings.Join(s.HWThrottleReasons, ", "))
+	}
+	
+	// A limiting ACPI thermal zone means the OS itself is slowing the CPU down
+	// for heat (passive cooling), at the firmware's request.
+	zoneLimited := s.HasSignal(sensors.SignalZones) && (s.ZonePassiveLimit < 100 || len(s.ZoneThrottleReasons) > 0)
+	if zoneLimited && !kernelThrottled && !hwThrottled {
+		instantState = StateThrottling
+		reason = fmt.Sprintf("ACPI passive cooling limits the CPU to %.0f%%", s.ZonePassiveLimit)
+		if s.HasSignal(sensors.SignalTemp) {
+			reason = fmt.Sprintf("%s (temp %.1fC)", reason, s.TempC)
+		}
+	}
+	if zoneLimited && len(s.ZoneThrottleReasons) > 0 {
+		reason = fmt.Sprintf("%s; thermal zones: %s", reason, strings.Join(s.ZoneThrottleReasons, ", "))
+	}
+	
+	// Without a temperature reading we cannot claim the system is thermally fine.
+	if !s.HasSignal(sensors.SignalTemp) && instantState == StateNormal {
+		reason = "Temperature unavailable; no thermal stress visible in other signals"
+		if why, ok := s.Rejected[sensors.SignalTemp]; ok {
+			reason = fmt.Sprintf("Temperature reading rejected (%s); no thermal stress visible in other signals", why)
+		}
+	}
+	
+	// CPU quota throttling slows a container down exactly like heat would, but
+	// it is the scheduler enforcing a limit. Report it as its own cause.
+	var causes []Cause
+	if instantState == StateThrottling {
+		causes = append(causes, CauseThermal)
+	}
+	if s.HasSignal(sensors.SignalQuota) && s.CgroupThrottledPeriods > 0 {
+		causes = append(causes, CauseQuota)
+		detail := fmt.Sprintf("%d of %d periods, %.0f ms held back", s.CgroupThrottledPeriods, s.CgroupPeriods, s.CgroupThrottledMs)
+		if instantState == StateNormal {
+			reason = fmt.Sprintf("CPU quota throttled, not thermal (%s)", detail)
+		} else {
+			reason = fmt.Sprintf("%s; also CPU quota throttled (%s)", reason, detail)
+		}
+	}
+	
+	// Throttling only hurts if something was waiting for the CPU. Pressure
+	// stall information tells an idle throttled machine from a stalled one.
+	var impact Impact
+	if len(causes) > 0 {
+		impact = EstimateImpact(s)
+		if impact != ImpactUnknown {
+			reason = fmt.Sprintf("%s; %s", reason, describeImpact(impact, s))
+		}
+	}
+	
+	// A starved VM looks slow and may show low clocks, but the cause is the
+	// hypervisor, not heat. Say so instead of letting it pass as idle.
+	if isStarved {
+		reason = fmt.Sprintf("%s; hypervisor steal %.0f%% (VM starved of CPU)", reason, s.StealPercent)
+	}
+	
 	// State Transition Logic with Hysteresis/Time-gating would go here.
 	// For a CLI "status" command (one-shot), we return the Instant State.
 	// For "watch", we would track transitions.
 	
 	// Simplified one-shot logic for 'status' command:
 	confidence := CalculateConfidence(s)
+	if kernelThrottled && s.Source(sensors.SignalThrottle).Provenance == sensors.ProvenanceMeasured {
+		confidence = ConfidenceHigh
+	}
+	if hwThrottled && s.Source(sensors.SignalThermStatus).Provenance == sensors.ProvenanceMeasured {
+		confidence = ConfidenceHigh
+	}
+	if zoneLimited && s.Source(sensors.SignalZones).Provenance == sensors.ProvenanceMeasured {
+		confidence = ConfidenceHigh
+	}
 	
 	return AnalysisResult{
+		Zone:       ZoneCPU,
 		State:      instantState,
 		Reason:     reason,
 		Confidence: confidence,
+		Causes:     causes,
+		Impact:     impact,
+		Thresholds: t,
 		Snapshot:   s,
 	}
 }
 
 // UpdateState is used by long-running process (watch) to handle transitions
+// Durations are measured on snapshot timestamps, so a replayed trace goes through
+// the same transitions as the live run it was recorded from, at any speed.
 func (sm *StateMachine) UpdateWithHistory(s *sensors.Snapshot) AnalysisResult {
 	instantResult := sm.Update(s)
+	now := s.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
 	
 	// Logic to transition from THROTTLING -> RECOVERY
 	// If we are currently THROTTLING, and instant state becomes NORMAL (Temp dropped),
@@ -130,12 +276,20 @@ func (sm *StateMachine) UpdateWithHistory(s *sensors.Snapshot) AnalysisResult {
 			// Temp has dropped. 
 			// We should transition to RECOVERY
 			sm.CurrentState = StateRecovery
-			sm.LastTransition = time.Now()
+			sm.LastTransition = now
 			
+			reason := "Temperature dropping, verifying stability"
+			if sm.zone() != ZoneCPU {
+				reason = fmt.Sprintf("%s back below its throttle point, verifying stability", zoneLabel(sm.Zone))
+			}
 			return AnalysisResult{
+				Zone:       instantResult.Zone,
 				State:      StateRecovery,
-				Reason:     "Temperature dropping, verifying stability",
+				Reason:     reason,
 				Confidence: instantResult.Confidence,
+				Causes:     instantResult.Causes,
+				Impact:     instantResult.Impact,
+				Thresholds: instantResult.Thresholds,
 				Snapshot:   s,
 			}
 		}
@@ -143,25 +297,33 @@ func (sm *StateMachine) UpdateWithHistory(s *sensors.Snapshot) AnalysisResult {
 	
 	if sm.CurrentState == StateRecovery {
 		// Stay in recovery for minimum duration
-		if time.Since(sm.LastTransition) < RecoverySustainDuration {
+		if now.Sub(sm.LastTransition) < RecoverySustainDuration {
+			reason := "Recovering..."
+			if sm.zone() != ZoneCPU {
+				reason = zoneLabel(sm.Zone) + " recovering from throttling"
+			}
 			return AnalysisResult{
+				Zone:       instantResult.Zone,
 				State:      StateRecovery,
-				Reason:     "Recovering...",
+				Reason:     reason,
 				Confidence: instantResult.Confidence,
+				Causes:     instantResult.Causes,
+				Impact:     instantResult.Impact,
+				Thresholds: instantResult.Thresholds,
 				Snapshot:   s,
 			}
 		}
 		// After duration, if still fine, go Normal
 		if instantResult.State == StateNormal {
 			sm.CurrentState = StateNormal
-			sm.LastTransition = time.Now()
+			sm.LastTransition = now
 		}
 	}
 	
 	// Default: if strong signal for new state, switch
 	if instantResult.State == StateThrottling {
 		sm.CurrentState = StateThrottling
-		sm.LastTransition = time.Now()
+		sm.LastTransition = now
 	} else if sm.CurrentState != StateRecovery {
 		// Normal/HeatStress updates
 		sm.CurrentState = instantResult.State
@@ -11,13 +11,45 @@ const (
 	TempCriticalThreshold = 95.0
 	TempRecoveryThreshold = 85.0
 
+	// Device zone thresholds (Celsius): high is heat stress, critical is where
+	// the device throttles itself, and a hot zone counts as cooled below recovery.
+	// NVMe drives typically throttle from 70-80°C (their WCTEMP).
+	NVMeHighC     = 70.0
+	NVMeCriticalC = 80.0
+	NVMeRecoveryC = 65.0
+	// SoCs of ARM boards start throttling around 80-85°C.
+	SoCHighC     = 80.0
+	SoCCriticalC = 85.0
+	SoCRecoveryC = 75.0
+	// Li-ion packs: chargers cut the charge rate above about 45°C, firmware
+	// limits discharge power above about 55°C.
+	BatteryHighC     = 45.0
+	BatteryCriticalC = 55.0
+	BatteryRecoveryC = 40.0
+
 	// Frequency Thresholds
 	FreqDropPercentage = 0.20 // 20% drop from base frequency suggests throttling
 
+	// Load Thresholds (percent)
+	HighLoadPercent  = 50.0
+	StealHighPercent = 10.0 // Above this, a VM is being starved by its hypervisor
+
+	// CPU pressure (PSI "some" avg10, percent of time tasks waited for a CPU)
+	PressureNoticeablePercent = 5.0  // Below this, throttling went unnoticed
+	PressureSeverePercent     = 25.0 // Above this, work was visibly stalled
+
+	// Fans
+	FanStallWindow     = 60 * time.Second // How far back a temperature rise is measured
+	FanStallTempRiseC  = 8.0              // A rise this large within the window should spin the fans up
+	FanStallMinTempC   = 60.0             // Below this, fan curves may legitimately keep fans slow or stopped
+	FanFlatPercent     = 5.0              // RPM changes smaller than this count as flat
+	FanFullDutyPercent = 98.0             // Duty cycle that counts as full speed
+
 	// Duration Thresholds
 	ThrottlingSustainDuration = 10 * time.Second
 	RecoverySustainDuration   = 30 * time.Second
 
 	// Sampling
 	SampleInterval = 2 * time.Second
+	TickDeadline   = 1500 * time.Millisecond // Collection budget per tick; leaves headroom for analysis and output
 )
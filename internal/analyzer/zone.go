@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// Zones analyzed. The CPU zone is judged on every CPU signal; the others on
+// their temperature alone, since drives, SoCs and batteries throttle themselves
+// out of sight of the CPU's clock and load.
+const (
+	ZoneCPU     = "cpu"
+	ZoneNVMe    = sensors.DeviceNVMe
+	ZoneSoC     = sensors.DeviceSoC
+	ZoneBattery = sensors.DeviceBattery
+)
+
+// ZoneThresholds are the temperature limits of one zone, in Celsius.
+type ZoneThresholds struct {
+	HighC     float64 // Heat stress from here
+	CriticalC float64 // The zone throttles from here
+	RecoveryC float64 // A hot zone has cooled down below this
+}
+
+// DefaultThresholds returns the limits of a zone, and false for a zone it has
+// no limits for.
+func DefaultThresholds(zone string) (ZoneThresholds, bool) {
+	switch zone {
+	case ZoneCPU:
+		return ZoneThresholds{TempHighThreshold, TempCriticalThreshold, TempRecoveryThreshold}, true
+	case ZoneNVMe:
+		return ZoneThresholds{NVMeHighC, NVMeCriticalC, NVMeRecoveryC}, true
+	case ZoneSoC:
+		return ZoneThresholds{SoCHighC, SoCCriticalC, SoCRecoveryC}, true
+	case ZoneBattery:
+		return ZoneThresholds{BatteryHighC, BatteryCriticalC, BatteryRecoveryC}, true
+	}
+	return ZoneThresholds{}, false
+}
+
+// zoneLabels name the device zones in reasons.
+var zoneLabels = map[string]string{
+	ZoneNVMe:    "NVMe drive",
+	ZoneSoC:     "SoC",
+	ZoneBattery: "Battery",
+}
+
+func zoneLabel(zone string) string {
+	if label, ok := zoneLabels[zone]; ok {
+		return label
+	}
+	return zone
+}
+
+// updateDevice judges a device zone on its temperature, with hysteresis: once
+// hot, the zone stays under heat stress until it is below RecoveryC.
+func (sm *StateMachine) updateDevice(s *sensors.Snapshot) AnalysisResult {
+	t := sm.thresholds()
+	label := zoneLabel(sm.Zone)
+	temp, ok := s.DeviceTempC[sm.Zone]
+	hasTemp := ok && s.HasSignal(sensors.SignalDeviceTemps)
+
+	state := StateNormal
+	reason := fmt.Sprintf("%s within normal temperature", label)
+	var causes []Cause
+	switch {
+	case !hasTemp:
+		reason = fmt.Sprintf("%s temperature unavailable", label)
+	case temp >= t.CriticalC:
+		state = StateThrottling
+		reason = fmt.Sprintf("%s at %.1fC, at or above its throttle point (%.0fC)", label, temp, t.CriticalC)
+		causes = append(causes, CauseThermal)
+	case temp >= t.HighC:
+		state = StateHeatStress
+		reason = fmt.Sprintf("%s hot (%.1fC)", label, temp)
+	case temp >= t.RecoveryC && sm.CurrentState != StateNormal:
+		state = StateHeatStress
+		reason = fmt.Sprintf("%s cooling down (%.1fC, normal below %.0fC)", label, temp, t.RecoveryC)
+	}
+
+	confidence := ConfidenceLow
+	switch s.Source(sensors.SignalDeviceTemps).Provenance {
+	case sensors.ProvenanceMeasured:
+		confidence = ConfidenceHigh
+	case sensors.ProvenanceEstimated, sensors.ProvenanceStale:
+		confidence = ConfidenceMedium
+	}
+	if !hasTemp {
+		confidence = ConfidenceLow
+	}
+
+	return AnalysisResult{
+		Zone:       sm.Zone,
+		State:      state,
+		Reason:     reason,
+		Confidence: confidence,
+		Causes:     causes,
+		Thresholds: t,
+		Snapshot:   s,
+	}
+}
+
+// DeviceZones runs one StateMachine per device zone (NVMe, SoC, battery).
+// Machines are created as zones show up in the snapshots and kept when a
+// zone's reading drops out, so it is reported as unavailable, not forgotten.
+// A zone with neither default nor configured thresholds is not analyzed; it
+// is listed in Ignored instead.
+type DeviceZones struct {
+	// Thresholds overrides DefaultThresholds for some zones, and gives
+	// unknown zones limits of their own.
+	Thresholds map[string]ZoneThresholds
+	// Ignored lists the zones seen without thresholds, in order of appearance.
+	Ignored []string
+
+	machines map[string]*StateMachine
+	ignored  map[string]bool
+}
+
+// NewDeviceZones returns an empty set of zone state machines.
+func NewDeviceZones() *DeviceZones {
+	return &DeviceZones{
+		Thresholds: map[string]ZoneThresholds{},
+		machines:   map[string]*StateMachine{},
+		ignored:    map[string]bool{},
+	}
+}
+
+// Update analyzes every device zone of s on its own, as StateMachine.Update does.
+func (d *DeviceZones) Update(s *sensors.Snapshot) []AnalysisResult {
+	var results []AnalysisResult
+	for _, zone := range d.zones(s) {
+		results = append(results, d.machines[zone].Update(s))
+	}
+	return results
+}
+
+// UpdateWithHistory analyzes every device zone of s with its state history.
+func (d *DeviceZones) UpdateWithHistory(s *sensors.Snapshot) []AnalysisResult {
+	var results []AnalysisResult
+	for _, zone := range d.zones(s) {
+		results = append(results, d.machines[zone].UpdateWithHistory(s))
+	}
+	return results
+}
+
+// zones returns the zones to analyze, sorted, creating machines for new ones.
+func (d *DeviceZones) zones(s *sensors.Snapshot) []string {
+	if s.HasSignal(sensors.SignalDeviceTemps) {
+		for zone := range s.DeviceTempC {
+			if _, ok := d.machines[zone]; ok || d.ignored[zone] {
+				continue
+			}
+			t, ok := d.Thresholds[zone]
+			if !ok {
+				t, ok = DefaultThresholds(zone)
+			}
+			if !ok {
+				d.ignored[zone] = true
+				d.Ignored = append(d.Ignored, zone)
+				continue
+			}
+			d.machines[zone] = NewZoneStateMachine(zone, t)
+		}
+	}
+	zones := make([]string, 0, len(d.machines))
+	for zone := range d.machines {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// deviceSnapshot returns a snapshot with measured device zone temperatures.
+func deviceSnapshot(at time.Time, temps map[string]float64) *sensors.Snapshot {
+	s := &sensors.Snapshot{Timestamp: at, DeviceTempC: temps}
+	s.SetSource(sensors.SignalDeviceTemps, sensors.ProvenanceMeasured, "test")
+	return s
+}
+
+func TestDeviceZonesIgnoreUnknownZones(t *testing.T) {
+	d := NewDeviceZones()
+	results := d.Update(deviceSnapshot(time.Now(), map[string]float64{ZoneNVMe: 60, "wifi": 95}))
+	if len(results) != 1 || results[0].Zone != ZoneNVMe {
+		t.Fatalf("results = %+v, want only the NVMe zone", results)
+	}
+	if len(d.Ignored) != 1 || d.Ignored[0] != "wifi" {
+		t.Errorf("Ignored = %v, want [wifi]", d.Ignored)
+	}
+	d.Update(deviceSnapshot(time.Now(), map[string]float64{"wifi": 96}))
+	if len(d.Ignored) != 1 {
+		t.Errorf("Ignored = %v, want wifi listed once", d.Ignored)
+	}
+}
+
+func TestDeviceZonesConfiguredThresholds(t *testing.T) {
+	d := NewDeviceZones()
+	custom := ZoneThresholds{HighC: 85, CriticalC: 95, RecoveryC: 80}
+	d.Thresholds[ZoneNVMe] = custom
+	d.Thresholds["wifi"] = ZoneThresholds{HighC: 70, CriticalC: 90, RecoveryC: 65}
+
+	results := d.Update(deviceSnapshot(time.Now(), map[string]float64{ZoneNVMe: 82, "wifi": 75}))
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want NVMe and wifi", results)
+	}
+	nvme, wifi := results[0], results[1]
+	if nvme.Thresholds != custom {
+		t.Errorf("NVMe thresholds = %+v, want %+v", nvme.Thresholds, custom)
+	}
+	if nvme.State != StateNormal {
+		t.Errorf("NVMe at 82C with an 85C limit = %s, want NORMAL", nvme.State)
+	}
+	if wifi.State != StateHeatStress {
+		t.Errorf("wifi at 75C with a 70C limit = %s, want HEAT_STRESS", wifi.State)
+	}
+}
+
+func TestDeviceZoneRecoveryReason(t *testing.T) {
+	d := NewDeviceZones()
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	var res AnalysisResult
+	for i, temp := range []float64{82, 60} {
+		res = d.UpdateWithHistory(deviceSnapshot(start.Add(time.Duration(i)*time.Minute), map[string]float64{ZoneNVMe: temp}))[0]
+	}
+	if res.State != StateRecovery {
+		t.Fatalf("state = %s, want RECOVERY", res.State)
+	}
+	if !strings.Contains(res.Reason, "NVMe drive") {
+		t.Errorf("reason = %q, want it to name the drive", res.Reason)
+	}
+	if res.Thresholds.CriticalC != NVMeCriticalC {
+		t.Errorf("recovery result lost its thresholds: %+v", res.Thresholds)
+	}
+}
@@ -6,7 +6,48 @@ import "time"
 // This struct maps to the JSONL log format.
 type Event struct {
 	Timestamp time.Time `json:"timestamp"`
-	Type      string    `json:"type"`              // e.g., TEMP_RISE, THROTTLING, RECOVERY
+	Type      string    `json:"type"`              // e.g., TEMP_RISE, THROTTLING, RECOVERY, FAN_STALL
 	State     string    `json:"state,omitempty"`   // e.g., NORMAL, HEAT_STRESS, THROTTLING
 	Details   string    `json:"details,omitempty"` // Human-readable details
+
+	// Signals maps each sensor signal to its provenance and backend,
+	// e.g. "TempC": "measured/linux". Lets the log tell real data from mocked data.
+	Signals map[string]string `json:"signals,omitempty"`
+
+	PowerW float64  `json:"power_w,omitempty"` // CPU package power at the time of the event
+	Causes []string `json:"causes,omitempty"`  // What held the CPU back, e.g. "thermal", "cpu-quota"
+
+	// CPU pressure (PSI "some" avg10, percent) and the impact estimated from it:
+	// "none" when the CPU was throttled but no task was waiting for it.
+	CPUPressure float64 `json:"cpu_pressure,omitempty"`
+	Impact      string  `json:"impact,omitempty"`
+
+	// Rejected lists signals that failed a sanity check, with the reason.
+	Rejected map[string]string `json:"rejected,omitempty"`
+	// Raw holds the unfiltered readings of smoothed signals, for forensic review;
+	// Details describes the filtered values the analysis used.
+	Raw map[string]float64 `json:"raw,omitempty"`
+
+	// PowerSource is "ac" or "battery" when the event happened, with the
+	// battery charge; empty on machines without a power supply signal.
+	PowerSource    string  `json:"power_source,omitempty"`
+	BatteryPercent float64 `json:"battery_percent,omitempty"`
+
+	// Fan and FanRPM identify the fan of a FAN_STALL or FAN_MAXED event.
+	Fan    string  `json:"fan,omitempty"`
+	FanRPM float64 `json:"fan_rpm,omitempty"`
+
+	// Session describes how a SESSION_START event's run reads its data:
+	// source, backend, CPU temperature mapping, filters.
+	Session map[string]string `json:"session,omitempty"`
+
+	// Zone is what a state event is about: "cpu", or a device zone such as
+	// "nvme", "soc" or "battery".
+	Zone string `json:"zone,omitempty"`
+}
+
+// CPUZone reports whether the event concerns the CPU. Events logged before
+// zones were tracked have no zone, and all concern the CPU.
+func (e Event) CPUZone() bool {
+	return e.Zone == "" || e.Zone == "cpu"
 }
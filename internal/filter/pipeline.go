@@ -0,0 +1,21 @@
+package filter
+
+import "thermal-throttling-analyzer/internal/sensors"
+
+// Pipeline is the stage between collection and analysis: readings that cannot
+// be real are rejected first, then the remaining signals are smoothed.
+type Pipeline struct {
+	Validator *Validator
+	Smoother  *Smoother
+}
+
+// NewPipeline returns a pipeline with the default limits and filter chains.
+func NewPipeline() *Pipeline {
+	return &Pipeline{Validator: NewValidator(), Smoother: NewSmoother()}
+}
+
+// Process validates and smooths s in place.
+func (p *Pipeline) Process(s *sensors.Snapshot) {
+	p.Validator.Check(s)
+	p.Smoother.Apply(s)
+}
@@ -0,0 +1,205 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// Sanity limits. Readings outside them cannot come from a working sensor.
+const (
+	MinTempC   = 5.0   // A running CPU is never this cold; 0 usually means "no reading"
+	MaxTempC   = 125.0 // Above any TjMax; the CPU would have shut down
+	MinFreqMHz = 100
+	MaxFreqMHz = 10000
+	MaxPowerW  = 1000.0
+
+	// FreqOverMaxFactor allows for rounding and the odd turbo bin above the rated maximum.
+	FreqOverMaxFactor = 1.1
+	// FreqOverBaseFactor is used instead when the maximum clock is unknown.
+	FreqOverBaseFactor = 2.5
+
+	// FrozenSamples is how many identical consecutive readings make an ACPI
+	// thermal zone temperature, or a clock at its rated maximum, count as stuck.
+	// Those sources are known to report constants.
+	FrozenSamples = 30
+	// FrozenTempSamples and FrozenLoadSwing apply to the other temperature
+	// sensors. coretemp and k10temp report whole degrees and sit flat for minutes
+	// on an idle machine, so a flat reading only counts as stuck when it lasts
+	// this long and the load swung by this many points meanwhile.
+	FrozenTempSamples = 300
+	FrozenLoadSwing   = 25.0
+)
+
+// acpiTempBackends take the CPU temperature from ACPI thermal zones.
+var acpiTempBackends = []string{"wmi", "perfcounter"}
+
+// BogusTemps are placeholder temperatures firmware reports instead of a reading.
+// 27.8°C (3010 tenths of a kelvin) is the classic MSAcpi_ThermalZoneTemperature
+// and acpitz constant on laptops whose ACPI thermal zone is not wired to the CPU.
+var BogusTemps = []float64{27.8, 27.85}
+
+// Validator removes readings that cannot be real from a snapshot before it is
+// analyzed. Rejected signals keep their value for the record, but are dropped
+// from ValidSignals with a reason. It remembers recent values to spot sensors
+// that are stuck, so one Validator should see every snapshot of a run.
+type Validator struct {
+	FrozenSamples     int
+	FrozenTempSamples int
+	FrozenLoadSwing   float64
+
+	frozen map[string]*frozenTrack
+}
+
+// frozenTrack counts how many consecutive samples a signal held the same value,
+// and the range the load moved through meanwhile.
+type frozenTrack struct {
+	value            float64
+	count            int
+	loadMin, loadMax float64
+	loadSeen         bool
+}
+
+// NewValidator returns a validator with the default limits.
+func NewValidator() *Validator {
+	return &Validator{
+		FrozenSamples:     FrozenSamples,
+		FrozenTempSamples: FrozenTempSamples,
+		FrozenLoadSwing:   FrozenLoadSwing,
+		frozen:            map[string]*frozenTrack{},
+	}
+}
+
+// Check validates s in place.
+func (v *Validator) Check(s *sensors.Snapshot) {
+	if s.HasSignal(sensors.SignalTemp) {
+		if reason := checkTemp(s.TempC); reason != "" {
+			s.Reject(sensors.SignalTemp, reason)
+		} else if reason := v.frozenTemp(s); reason != "" {
+			s.Reject(sensors.SignalTemp, reason)
+		}
+	}
+
+	if s.HasSignal(sensors.SignalFreq) {
+		if reason := checkFreq(s); reason != "" {
+			s.Reject(sensors.SignalFreq, reason)
+		} else if n := v.track(s, sensors.SignalFreq, float64(s.FreqMHz)); v.FrozenSamples > 0 && n >= v.FrozenSamples && s.FreqMHz == limitFreq(s) {
+			// Some firmware reports the rated clock as the current one. A clock
+			// that never moves off its maximum is a copy of it, not a reading.
+			s.Reject(sensors.SignalFreq, fmt.Sprintf("stuck at the rated %d MHz for %d samples", s.FreqMHz, v.FrozenSamples))
+		}
+	}
+
+	if s.HasSignal(sensors.SignalBaseFreq) && (s.BaseFreqMHz < MinFreqMHz || s.BaseFreqMHz > MaxFreqMHz) {
+		s.Reject(sensors.SignalBaseFreq, fmt.Sprintf("%d MHz is outside %d-%d MHz", s.BaseFreqMHz, MinFreqMHz, MaxFreqMHz))
+	}
+
+	if s.HasSignal(sensors.SignalLoad) && (s.LoadPercent < 0 || s.LoadPercent > 100) {
+		s.Reject(sensors.SignalLoad, fmt.Sprintf("%.0f%% is outside 0-100%%", s.LoadPercent))
+	}
+
+	if s.HasSignal(sensors.SignalPower) && (s.PowerW < 0 || s.PowerW > MaxPowerW) {
+		s.Reject(sensors.SignalPower, fmt.Sprintf("%.0f W is outside 0-%.0f W", s.PowerW, MaxPowerW))
+	}
+}
+
+func checkTemp(t float64) string {
+	for _, bogus := range BogusTemps {
+		if math.Abs(t-bogus) < 0.01 {
+			return fmt.Sprintf("%.1f°C is a known ACPI placeholder, not a CPU reading", t)
+		}
+	}
+	if t < MinTempC || t > MaxTempC {
+		return fmt.Sprintf("%.1f°C is outside %.0f-%.0f°C", t, MinTempC, MaxTempC)
+	}
+	return ""
+}
+
+func checkFreq(s *sensors.Snapshot) string {
+	if s.FreqMHz < MinFreqMHz || s.FreqMHz > MaxFreqMHz {
+		return fmt.Sprintf("%d MHz is outside %d-%d MHz", s.FreqMHz, MinFreqMHz, MaxFreqMHz)
+	}
+	if s.MaxFreqMHz > 0 && float64(s.FreqMHz) > float64(s.MaxFreqMHz)*FreqOverMaxFactor {
+		return fmt.Sprintf("%d MHz is above the %d MHz maximum", s.FreqMHz, s.MaxFreqMHz)
+	}
+	if s.MaxFreqMHz == 0 && s.BaseFreqMHz > 0 && float64(s.FreqMHz) > float64(s.BaseFreqMHz)*FreqOverBaseFactor {
+		return fmt.Sprintf("%d MHz is implausibly far above the %d MHz base clock", s.FreqMHz, s.BaseFreqMHz)
+	}
+	return ""
+}
+
+// limitFreq is the rated clock a stuck reading would be copied from.
+func limitFreq(s *sensors.Snapshot) int {
+	if s.MaxFreqMHz > 0 {
+		return s.MaxFreqMHz
+	}
+	return s.BaseFreqMHz
+}
+
+// frozenTemp reports why the temperature counts as stuck, or "".
+// ACPI thermal zones are judged on repetition alone; other sensors only when
+// the load swung while the reading did not move.
+func (v *Validator) frozenTemp(s *sensors.Snapshot) string {
+	count := v.track(s, sensors.SignalTemp, s.TempC)
+	if acpiTemp(s) {
+		if v.FrozenSamples > 0 && count >= v.FrozenSamples {
+			return fmt.Sprintf("frozen at %.1f°C for %d samples", s.TempC, count)
+		}
+		return ""
+	}
+	track := v.frozen[sensors.SignalTemp]
+	if v.FrozenTempSamples <= 0 || count < v.FrozenTempSamples || track == nil || track.loadMax-track.loadMin < v.FrozenLoadSwing {
+		return ""
+	}
+	return fmt.Sprintf("frozen at %.1f°C for %d samples while the load moved between %.0f%% and %.0f%%",
+		s.TempC, count, track.loadMin, track.loadMax)
+}
+
+// acpiTemp reports whether the temperature comes from an ACPI thermal zone.
+func acpiTemp(s *sensors.Snapshot) bool {
+	for _, b := range acpiTempBackends {
+		if s.Source(sensors.SignalTemp).Backend == b {
+			return true
+		}
+	}
+	for _, sel := range s.TempSensors {
+		lower := strings.ToLower(sel)
+		if strings.Contains(lower, "acpitz") || strings.Contains(lower, "msacpi") {
+			return true
+		}
+	}
+	return false
+}
+
+// track records value and returns for how many fresh readings in a row the
+// signal has held it, widening the load range seen meanwhile. Stale values
+// repeat by design and synthetic ones may be flat on purpose, so only real
+// readings are counted.
+func (v *Validator) track(s *sensors.Snapshot, name string, value float64) int {
+	switch s.Source(name).Provenance {
+	case sensors.ProvenanceMeasured, sensors.ProvenanceEstimated:
+	default:
+		return 0
+	}
+	if v.frozen == nil {
+		v.frozen = map[string]*frozenTrack{}
+	}
+	t, ok := v.frozen[name]
+	if !ok || t.value != value {
+		t = &frozenTrack{value: value}
+		v.frozen[name] = t
+	}
+	t.count++
+	if s.HasSignal(sensors.SignalLoad) {
+		if !t.loadSeen || s.LoadPercent < t.loadMin {
+			t.loadMin = s.LoadPercent
+		}
+		if !t.loadSeen || s.LoadPercent > t.loadMax {
+			t.loadMax = s.LoadPercent
+		}
+		t.loadSeen = true
+	}
+	return t.count
+}
@@ -0,0 +1,167 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// snapshot returns a snapshot with the core signals measured by backend.
+func snapshot(backend string, tempC float64, freqMHz int, load float64) *sensors.Snapshot {
+	s := &sensors.Snapshot{
+		TempC:       tempC,
+		FreqMHz:     freqMHz,
+		MaxFreqMHz:  4000,
+		BaseFreqMHz: 2000,
+		LoadPercent: load,
+	}
+	for _, name := range []string{sensors.SignalTemp, sensors.SignalFreq, sensors.SignalBaseFreq, sensors.SignalLoad} {
+		s.SetSource(name, sensors.ProvenanceMeasured, backend)
+	}
+	return s
+}
+
+func TestValidatorRejectsImpossibleReadings(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(s *sensors.Snapshot)
+		signal string
+		reason string
+	}{
+		{"ACPI placeholder", func(s *sensors.Snapshot) { s.TempC = 27.8 }, sensors.SignalTemp, "ACPI placeholder"},
+		{"ACPI placeholder rounded", func(s *sensors.Snapshot) { s.TempC = 27.85 }, sensors.SignalTemp, "ACPI placeholder"},
+		{"no temperature", func(s *sensors.Snapshot) { s.TempC = 0 }, sensors.SignalTemp, "outside"},
+		{"too hot", func(s *sensors.Snapshot) { s.TempC = 200 }, sensors.SignalTemp, "outside"},
+		{"0 MHz", func(s *sensors.Snapshot) { s.FreqMHz = 0 }, sensors.SignalFreq, "outside"},
+		{"above maximum", func(s *sensors.Snapshot) { s.FreqMHz = 4500 }, sensors.SignalFreq, "above the 4000 MHz maximum"},
+		{"far above base", func(s *sensors.Snapshot) { s.MaxFreqMHz, s.FreqMHz = 0, 5100 }, sensors.SignalFreq, "above the 2000 MHz base"},
+		{"load over 100%", func(s *sensors.Snapshot) { s.LoadPercent = 140 }, sensors.SignalLoad, "outside"},
+		{"0 MHz base", func(s *sensors.Snapshot) { s.BaseFreqMHz = 0 }, sensors.SignalBaseFreq, "outside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := snapshot("linux", 60, 3000, 50)
+			tt.edit(s)
+			NewValidator().Check(s)
+			if s.HasSignal(tt.signal) {
+				t.Fatalf("%s not rejected", tt.signal)
+			}
+			if !strings.Contains(s.Rejected[tt.signal], tt.reason) {
+				t.Errorf("reason = %q, want it to mention %q", s.Rejected[tt.signal], tt.reason)
+			}
+			if len(s.Rejected) != 1 {
+				t.Errorf("rejected %v, want only %s", s.Rejected, tt.signal)
+			}
+		})
+	}
+}
+
+func TestValidatorAcceptsPlausibleReadings(t *testing.T) {
+	for _, s := range []*sensors.Snapshot{
+		snapshot("linux", 27.0, 800, 0),
+		snapshot("linux", 99, 4400, 100), // One turbo bin above the rated maximum
+	} {
+		NewValidator().Check(s)
+		if len(s.Rejected) > 0 {
+			t.Errorf("rejected %v", s.Rejected)
+		}
+	}
+}
+
+// feed runs n snapshots through v and returns the last one.
+func feed(v *Validator, n int, next func(i int) *sensors.Snapshot) *sensors.Snapshot {
+	var s *sensors.Snapshot
+	for i := 0; i < n; i++ {
+		s = next(i)
+		v.Check(s)
+	}
+	return s
+}
+
+func TestFrozenACPITemperature(t *testing.T) {
+	v := NewValidator()
+	s := feed(v, FrozenSamples-1, func(i int) *sensors.Snapshot { return snapshot("wmi", 45, 2600, 10) })
+	if !s.HasSignal(sensors.SignalTemp) {
+		t.Fatalf("rejected after %d samples: %s", FrozenSamples-1, s.Rejected[sensors.SignalTemp])
+	}
+	s = feed(v, 1, func(i int) *sensors.Snapshot { return snapshot("wmi", 45, 2600, 10) })
+	if s.HasSignal(sensors.SignalTemp) {
+		t.Fatalf("ACPI zone flat for %d samples not rejected", FrozenSamples)
+	}
+	// The rejection clears as soon as the value moves.
+	s = feed(v, 1, func(i int) *sensors.Snapshot { return snapshot("wmi", 46, 2600, 10) })
+	if !s.HasSignal(sensors.SignalTemp) {
+		t.Errorf("still rejected after the value changed: %s", s.Rejected[sensors.SignalTemp])
+	}
+}
+
+func TestFrozenMappedACPIZone(t *testing.T) {
+	v := NewValidator()
+	s := feed(v, FrozenSamples, func(i int) *sensors.Snapshot {
+		s := snapshot("linux", 50, 2600, 10)
+		s.TempSensors = []string{"zone:acpitz/thermal_zone0"}
+		return s
+	})
+	if s.HasSignal(sensors.SignalTemp) {
+		t.Error("acpitz zone flat for 30 samples not rejected")
+	}
+}
+
+func TestIdleWholeDegreeSensorNotFrozen(t *testing.T) {
+	// coretemp on an idle machine: whole degrees, flat for many minutes.
+	v := NewValidator()
+	s := feed(v, 2*FrozenTempSamples, func(i int) *sensors.Snapshot {
+		return snapshot("linux", 41, 800+i%3*100, float64(2+i%4))
+	})
+	if !s.HasSignal(sensors.SignalTemp) {
+		t.Errorf("idle flat temperature rejected: %s", s.Rejected[sensors.SignalTemp])
+	}
+}
+
+func TestFlatTemperatureUnderChangingLoad(t *testing.T) {
+	load := func(i int) float64 {
+		if i%60 < 30 {
+			return 5
+		}
+		return 95
+	}
+	v := NewValidator()
+	s := feed(v, FrozenTempSamples-1, func(i int) *sensors.Snapshot { return snapshot("linux", 41, 3000, load(i)) })
+	if !s.HasSignal(sensors.SignalTemp) {
+		t.Fatalf("rejected before %d samples: %s", FrozenTempSamples, s.Rejected[sensors.SignalTemp])
+	}
+	s = feed(v, 1, func(i int) *sensors.Snapshot { return snapshot("linux", 41, 3000, 95) })
+	if s.HasSignal(sensors.SignalTemp) {
+		t.Fatal("temperature flat while the load swung between 5% and 95% not rejected")
+	}
+	if !strings.Contains(s.Rejected[sensors.SignalTemp], "load moved") {
+		t.Errorf("reason = %q", s.Rejected[sensors.SignalTemp])
+	}
+}
+
+func TestStaleReadingsNotCountedAsFrozen(t *testing.T) {
+	v := NewValidator()
+	s := feed(v, 2*FrozenSamples, func(i int) *sensors.Snapshot {
+		s := snapshot("wmi", 45, 2600, 10)
+		s.SetSource(sensors.SignalTemp, sensors.ProvenanceStale, "wmi")
+		return s
+	})
+	if _, rejected := s.Rejected[sensors.SignalTemp]; rejected {
+		t.Errorf("stale repeats rejected: %s", s.Rejected[sensors.SignalTemp])
+	}
+}
+
+func TestFreqStuckAtRatedMaximum(t *testing.T) {
+	v := NewValidator()
+	s := feed(v, FrozenSamples, func(i int) *sensors.Snapshot { return snapshot("wmi", float64(40+i%5), 4000, 50) })
+	if s.HasSignal(sensors.SignalFreq) {
+		t.Error("clock stuck at the rated maximum not rejected")
+	}
+
+	v = NewValidator()
+	s = feed(v, FrozenSamples, func(i int) *sensors.Snapshot { return snapshot("wmi", float64(40+i%5), 3100, 50) })
+	if !s.HasSignal(sensors.SignalFreq) {
+		t.Errorf("steady clock below the maximum rejected: %s", s.Rejected[sensors.SignalFreq])
+	}
+}
@@ -0,0 +1,225 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// Stage is one step of a signal's filter chain. Stages keep state from one
+// snapshot to the next, so a chain belongs to a single run.
+type Stage interface {
+	Apply(value float64, at time.Time) float64
+	String() string
+}
+
+// EMA is an exponential moving average. Alpha is the weight of the newest
+// reading: 1 passes readings through, smaller values smooth harder.
+type EMA struct {
+	Alpha float64
+
+	primed bool
+	value  float64
+}
+
+func (e *EMA) Apply(value float64, at time.Time) float64 {
+	if !e.primed {
+		e.primed = true
+		e.value = value
+		return value
+	}
+	e.value += e.Alpha * (value - e.value)
+	return e.value
+}
+
+func (e *EMA) String() string { return fmt.Sprintf("ema:%g", e.Alpha) }
+
+// RateLimit rejects spikes: a reading that moved faster than MaxPerSecond
+// since the last accepted one is replaced by that last value. A change that
+// persists for more than MaxHold readings is real and gets accepted.
+type RateLimit struct {
+	MaxPerSecond float64
+	MaxHold      int
+
+	primed bool
+	value  float64
+	at     time.Time
+	held   int
+}
+
+func (r *RateLimit) Apply(value float64, at time.Time) float64 {
+	if r.primed {
+		dt := at.Sub(r.at).Seconds()
+		if dt > 0 && math.Abs(value-r.value)/dt > r.MaxPerSecond && r.held < r.MaxHold {
+			r.held++
+			return r.value
+		}
+	}
+	r.primed = true
+	r.value = value
+	r.at = at
+	r.held = 0
+	return value
+}
+
+func (r *RateLimit) String() string { return fmt.Sprintf("rate:%g", r.MaxPerSecond) }
+
+// DefaultMaxHold is how many readings a rate limit holds back before accepting a step.
+const DefaultMaxHold = 2
+
+// Chain is the filter configuration of one signal. Oversample is applied by
+// the collector (median of that many reads within a tick); the stages run on
+// the result, in order.
+type Chain struct {
+	Oversample int
+	Stages     []Stage
+}
+
+// ParseChain parses a chain such as "median:3,rate:20,ema:0.5", or "off".
+//
+//	median:N  read the signal N times per tick and keep the median
+//	rate:R    reject readings changing faster than R units per second
+//	ema:A     exponential moving average with weight A (0-1] for new readings
+func ParseChain(spec string) (*Chain, error) {
+	c := &Chain{}
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "off" {
+		return c, nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		kind, arg, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("filter %q needs a parameter, e.g. %s:3", part, part)
+		}
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid parameter in filter %q", part)
+		}
+		switch kind {
+		case "median":
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("median needs a whole number of samples: %q", part)
+			}
+			c.Oversample = int(v)
+		case "rate":
+			c.Stages = append(c.Stages, &RateLimit{MaxPerSecond: v, MaxHold: DefaultMaxHold})
+		case "ema":
+			if v > 1 {
+				return nil, fmt.Errorf("ema weight must be at most 1: %q", part)
+			}
+			c.Stages = append(c.Stages, &EMA{Alpha: v})
+		default:
+			return nil, fmt.Errorf("unknown filter %q (use median, rate or ema)", kind)
+		}
+	}
+	return c, nil
+}
+
+func (c *Chain) String() string {
+	var parts []string
+	if c.Oversample > 1 {
+		parts = append(parts, fmt.Sprintf("median:%d", c.Oversample))
+	}
+	for _, st := range c.Stages {
+		parts = append(parts, st.String())
+	}
+	if len(parts) == 0 {
+		return "off"
+	}
+	return strings.Join(parts, ",")
+}
+
+// DefaultChains are the chains a smoother starts with. Smoothing costs extra
+// reads and delays detection, so no signal is filtered unless --filter asks.
+var DefaultChains = map[string]string{}
+
+// smoothable maps the signals a chain can run on to their snapshot fields.
+var smoothable = map[string]struct {
+	get func(s *sensors.Snapshot) float64
+	set func(s *sensors.Snapshot, v float64)
+}{
+	sensors.SignalTemp: {
+		func(s *sensors.Snapshot) float64 { return s.TempC },
+		func(s *sensors.Snapshot, v float64) { s.TempC = v },
+	},
+	sensors.SignalFreq: {
+		func(s *sensors.Snapshot) float64 { return float64(s.FreqMHz) },
+		func(s *sensors.Snapshot, v float64) { s.FreqMHz = int(math.Round(v)) },
+	},
+	sensors.SignalLoad: {
+		func(s *sensors.Snapshot) float64 { return s.LoadPercent },
+		func(s *sensors.Snapshot, v float64) { s.LoadPercent = v },
+	},
+	sensors.SignalPower: {
+		func(s *sensors.Snapshot) float64 { return s.PowerW },
+		func(s *sensors.Snapshot, v float64) { s.PowerW = v },
+	},
+}
+
+// Smoother runs each signal's chain over successive snapshots.
+type Smoother struct {
+	chains map[string]*Chain
+}
+
+// NewSmoother returns a smoother with the DefaultChains.
+func NewSmoother() *Smoother {
+	sm := &Smoother{chains: map[string]*Chain{}}
+	for signal, spec := range DefaultChains {
+		c, err := ParseChain(spec)
+		if err != nil {
+			panic(fmt.Sprintf("default filter for %s: %v", signal, err))
+		}
+		sm.chains[signal] = c
+	}
+	return sm
+}
+
+// Set replaces a signal's chain. Only TempC, FreqMHz, LoadPercent and PowerW can be filtered.
+func (sm *Smoother) Set(signal string, c *Chain) error {
+	if _, ok := smoothable[signal]; !ok {
+		return fmt.Errorf("signal %s cannot be filtered (use %s)", signal, strings.Join(SmoothableSignals(), ", "))
+	}
+	sm.chains[signal] = c
+	return nil
+}
+
+// Chains returns every configured chain by signal.
+func (sm *Smoother) Chains() map[string]*Chain {
+	return sm.chains
+}
+
+// SmoothableSignals lists the signals a chain can be set for, sorted.
+func SmoothableSignals() []string {
+	var names []string
+	for name := range smoothable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply filters the valid signals of s in place. The unfiltered values are
+// kept in s.Raw.
+func (sm *Smoother) Apply(s *sensors.Snapshot) {
+	for signal, c := range sm.chains {
+		if len(c.Stages) == 0 || !s.HasSignal(signal) {
+			continue
+		}
+		field := smoothable[signal]
+		raw := field.get(s)
+		v := raw
+		for _, st := range c.Stages {
+			v = st.Apply(v, s.Timestamp)
+		}
+		if s.Raw == nil {
+			s.Raw = make(map[string]float64)
+		}
+		s.Raw[signal] = raw
+		field.set(s, v)
+	}
+}
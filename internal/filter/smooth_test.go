@@ -0,0 +1,166 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+func TestParseChain(t *testing.T) {
+	tests := []struct {
+		spec       string
+		want       string // String() of the parsed chain
+		oversample int
+	}{
+		{"", "off", 0},
+		{"off", "off", 0},
+		{" median:3 ", "median:3", 3},
+		{"rate:25", "rate:25", 0},
+		{"ema:0.5", "ema:0.5", 0},
+		{"median:5, rate:20, ema:0.3", "median:5,rate:20,ema:0.3", 5},
+		{"ema:0.3,rate:20", "ema:0.3,rate:20", 0},
+	}
+	for _, tt := range tests {
+		c, err := ParseChain(tt.spec)
+		if err != nil {
+			t.Errorf("ParseChain(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := c.String(); got != tt.want {
+			t.Errorf("ParseChain(%q).String() = %q, want %q", tt.spec, got, tt.want)
+		}
+		if c.Oversample != tt.oversample {
+			t.Errorf("ParseChain(%q).Oversample = %d, want %d", tt.spec, c.Oversample, tt.oversample)
+		}
+		// The printed form parses back to the same chain.
+		again, err := ParseChain(c.String())
+		if err != nil || again.String() != c.String() {
+			t.Errorf("ParseChain(%q) does not round trip: %v, %v", c.String(), again, err)
+		}
+	}
+}
+
+func TestParseChainErrors(t *testing.T) {
+	for _, spec := range []string{
+		"median",      // no parameter
+		"median:2.5",  // not a whole number
+		"median:0",    // not positive
+		"rate:-1",     // not positive
+		"rate:fast",   // not a number
+		"ema:1.5",     // weight above 1
+		"kalman:0.1",  // unknown stage
+		"rate:20,ema", // second stage without parameter
+	} {
+		if c, err := ParseChain(spec); err == nil {
+			t.Errorf("ParseChain(%q) = %v, want an error", spec, c)
+		}
+	}
+}
+
+func TestEMA(t *testing.T) {
+	e := &EMA{Alpha: 0.5}
+	at := time.Unix(0, 0)
+	want := []float64{60, 70, 75, 77.5}
+	for i, in := range []float64{60, 80, 80, 80} {
+		if got := e.Apply(in, at); got != want[i] {
+			t.Errorf("reading %d: EMA = %v, want %v", i, got, want[i])
+		}
+	}
+
+	pass := &EMA{Alpha: 1}
+	for _, in := range []float64{40, 90, 55} {
+		if got := pass.Apply(in, at); got != in {
+			t.Errorf("EMA with alpha 1 = %v, want %v", got, in)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	start := time.Unix(0, 0)
+	tick := func(i int) time.Time { return start.Add(time.Duration(i) * 2 * time.Second) }
+
+	t.Run("spike is held back", func(t *testing.T) {
+		r := &RateLimit{MaxPerSecond: 5, MaxHold: 2}
+		in := []float64{60, 62, 95, 63, 64}
+		want := []float64{60, 62, 62, 63, 64}
+		for i := range in {
+			if got := r.Apply(in[i], tick(i)); got != want[i] {
+				t.Errorf("reading %d: got %v, want %v", i, got, want[i])
+			}
+		}
+	})
+
+	t.Run("lasting step is accepted after MaxHold readings", func(t *testing.T) {
+		r := &RateLimit{MaxPerSecond: 5, MaxHold: 2}
+		in := []float64{60, 90, 90, 90, 90}
+		want := []float64{60, 60, 60, 90, 90}
+		for i := range in {
+			if got := r.Apply(in[i], tick(i)); got != want[i] {
+				t.Errorf("reading %d: got %v, want %v", i, got, want[i])
+			}
+		}
+	})
+
+	t.Run("slow change passes", func(t *testing.T) {
+		r := &RateLimit{MaxPerSecond: 5, MaxHold: 2}
+		for i, in := range []float64{60, 68, 76, 84} {
+			if got := r.Apply(in, tick(i)); got != in {
+				t.Errorf("reading %d: got %v, want %v", i, got, in)
+			}
+		}
+	})
+}
+
+func TestSmootherApply(t *testing.T) {
+	sm := NewSmoother()
+	c, err := ParseChain("ema:0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Set(sensors.SignalTemp, c); err != nil {
+		t.Fatal(err)
+	}
+
+	first := snapshot("hwmon", 60, 3000, 50)
+	sm.Apply(first)
+	second := snapshot("hwmon", 80, 3000, 50)
+	sm.Apply(second)
+	if second.TempC != 70 {
+		t.Errorf("smoothed TempC = %v, want 70", second.TempC)
+	}
+	if second.Raw[sensors.SignalTemp] != 80 {
+		t.Errorf("Raw[TempC] = %v, want 80", second.Raw[sensors.SignalTemp])
+	}
+	if _, ok := second.Raw[sensors.SignalFreq]; ok {
+		t.Error("unfiltered FreqMHz was recorded in Raw")
+	}
+
+	// A rejected reading is left alone and does not feed the chain.
+	bad := snapshot("hwmon", 0, 3000, 50)
+	bad.Reject(sensors.SignalTemp, "test")
+	sm.Apply(bad)
+	if bad.TempC != 0 || bad.Raw != nil {
+		t.Errorf("rejected TempC was filtered: %v, raw %v", bad.TempC, bad.Raw)
+	}
+	third := snapshot("hwmon", 80, 3000, 50)
+	sm.Apply(third)
+	if third.TempC != 75 {
+		t.Errorf("smoothed TempC after a rejected reading = %v, want 75", third.TempC)
+	}
+
+	if err := sm.Set(sensors.SignalBaseFreq, c); err == nil {
+		t.Error("Set accepted a chain for BaseFreqMHz")
+	}
+}
+
+func TestDefaultSmootherPassesThrough(t *testing.T) {
+	sm := NewSmoother()
+	for _, temp := range []float64{60, 95, 61} {
+		s := snapshot("hwmon", temp, 3000, 50)
+		sm.Apply(s)
+		if s.TempC != temp || s.Raw != nil {
+			t.Errorf("default smoother changed TempC %v to %v", temp, s.TempC)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package scenario
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+// BuiltinNames lists the scenarios shipped with tta.
+func BuiltinNames() []string {
+	entries, _ := builtinFS.ReadDir("builtin")
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builtin returns a built-in scenario by name.
+func Builtin(name string) (*Scenario, error) {
+	data, err := builtinFS.ReadFile(path.Join("builtin", name+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown scenario %q (built-in: %s)", name, strings.Join(BuiltinNames(), ", "))
+	}
+	sc, err := Parse(string(data), name)
+	if err != nil {
+		return nil, fmt.Errorf("built-in scenario %s: %v", name, err)
+	}
+	return sc, nil
+}
+
+// Resolve loads a scenario file, or a built-in scenario when no such file exists.
+func Resolve(nameOrPath string) (*Scenario, error) {
+	if _, err := os.Stat(nameOrPath); err == nil {
+		return Load(nameOrPath)
+	}
+	return Builtin(nameOrPath)
+}
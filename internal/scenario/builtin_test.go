@@ -0,0 +1,21 @@
+package scenario
+
+import "testing"
+
+func TestBuiltinScenariosPass(t *testing.T) {
+	names := BuiltinNames()
+	if len(names) == 0 {
+		t.Fatal("no built-in scenarios")
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			sc, err := Builtin(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sc.Check(nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
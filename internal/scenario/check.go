@@ -0,0 +1,70 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"thermal-throttling-analyzer/internal/analyzer"
+	"thermal-throttling-analyzer/internal/filter"
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// StateSequence runs snapshots through a filter pipeline and a fresh state
+// machine, as watch does, and returns the states it went through with
+// consecutive repeats collapsed.
+func StateSequence(snaps []*sensors.Snapshot, pipeline *filter.Pipeline) []string {
+	sm := analyzer.NewStateMachine()
+	var states []string
+	for _, s := range snaps {
+		pipeline.Process(s)
+		state := string(sm.UpdateWithHistory(s).State)
+		if len(states) == 0 || states[len(states)-1] != state {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// Check generates the scenario and compares the state machine's path with Expect.
+// A nil pipeline uses the scenario's own filters.
+func (sc *Scenario) Check(pipeline *filter.Pipeline) error {
+	if len(sc.Expect) == 0 {
+		return fmt.Errorf("scenario %s has no expected states", sc.Name)
+	}
+	if pipeline == nil {
+		var err error
+		if pipeline, err = sc.Pipeline(); err != nil {
+			return err
+		}
+	}
+	return sc.CheckStates(StateSequence(sc.Generate(), pipeline))
+}
+
+// Pipeline returns a fresh filter pipeline with the chains the scenario
+// declares. Generated readings are exact, so oversampling has nothing to
+// work on and is refused.
+func (sc *Scenario) Pipeline() (*filter.Pipeline, error) {
+	p := filter.NewPipeline()
+	for signal, spec := range sc.Filter {
+		c, err := filter.ParseChain(spec)
+		if err != nil {
+			return nil, fmt.Errorf("filter: %s: %v", signal, err)
+		}
+		if c.Oversample > 1 {
+			return nil, fmt.Errorf("filter: %s: median needs real sensors", signal)
+		}
+		if err := p.Smoother.Set(signal, c); err != nil {
+			return nil, fmt.Errorf("filter: %v", err)
+		}
+	}
+	return p, nil
+}
+
+// CheckStates compares an observed state sequence with Expect.
+func (sc *Scenario) CheckStates(got []string) error {
+	if strings.Join(got, ",") != strings.Join(sc.Expect, ",") {
+		return fmt.Errorf("scenario %s: expected states %s, got %s",
+			sc.Name, strings.Join(sc.Expect, " → "), strings.Join(got, " → "))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package scenario
+
+import (
+	"math/rand"
+	"time"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// Backend is the backend name on generated snapshots. Everything a scenario
+// produces is marked mocked.
+const Backend = "scenario"
+
+// Epoch is the timestamp of a scenario's first snapshot. Players rebase it to
+// the wall clock when needed; a fixed value keeps generation deterministic.
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// generator holds the values that persist from one segment to the next.
+type generator struct {
+	sc   *Scenario
+	rng  *rand.Rand
+	out  []*sensors.Snapshot
+	temp float64
+	freq int
+	load float64
+}
+
+// Generate renders the scenario as one snapshot per Interval.
+func (sc *Scenario) Generate() []*sensors.Snapshot {
+	g := &generator{
+		sc:   sc,
+		rng:  rand.New(rand.NewSource(sc.Seed)),
+		temp: sc.Start.TempC,
+		freq: sc.Start.FreqMHz,
+		load: sc.Start.LoadPercent,
+	}
+	for _, seg := range sc.Segments {
+		g.segment(seg)
+	}
+	return g.out
+}
+
+func (g *generator) segment(seg Segment) {
+	ticks := int(seg.Duration / g.sc.Interval)
+	if seg.Duration > 0 && ticks == 0 {
+		ticks = 1
+	}
+
+	switch seg.Kind {
+	case KindLoadStep:
+		g.load = seg.Load
+		if seg.FreqMHz > 0 {
+			g.freq = seg.FreqMHz
+		}
+	}
+
+	from := g.temp
+	for k := 0; k < ticks; k++ {
+		temp, freq := g.temp, g.freq
+		var missing []string
+
+		switch seg.Kind {
+		case KindRamp:
+			temp = from + (seg.To-from)*float64(k+1)/float64(ticks)
+		case KindSpike:
+			temp += seg.Delta
+		case KindNoise:
+			temp += (g.rng.Float64()*2 - 1) * seg.Amplitude
+		case KindFreqSag:
+			freq = int(float64(freq) * (1 - seg.Percent/100))
+		case KindDropout:
+			missing = seg.Signals
+		}
+		g.emit(temp, freq, missing)
+	}
+
+	// Only ramps leave the temperature changed; spikes and noise fall back.
+	if seg.Kind == KindRamp {
+		g.temp = seg.To
+	}
+}
+
+func (g *generator) emit(temp float64, freq int, missing []string) {
+	s := &sensors.Snapshot{
+		Timestamp:    Epoch.Add(time.Duration(len(g.out)) * g.sc.Interval),
+		TempC:        temp,
+		FreqMHz:      freq,
+		FreqMinMHz:   freq,
+		FreqMaxMHz:   freq,
+		BaseFreqMHz:  g.sc.Start.BaseFreqMHz,
+		LoadPercent:  g.load,
+		ValidSignals: []string{},
+		Sources:      map[string]sensors.SignalSource{},
+	}
+	for _, name := range sensors.CoreSignals {
+		s.SetSource(name, sensors.ProvenanceMocked, Backend)
+	}
+	// A missing signal holds no value, as with a real backend.
+	for _, name := range missing {
+		switch name {
+		case sensors.SignalTemp:
+			s.TempC = 0
+		case sensors.SignalFreq:
+			s.FreqMHz, s.FreqMinMHz, s.FreqMaxMHz = 0, 0, 0
+		case sensors.SignalBaseFreq:
+			s.BaseFreqMHz = 0
+		case sensors.SignalLoad:
+			s.LoadPercent = 0
+		}
+		s.SetSource(name, sensors.ProvenanceMissing, Backend)
+	}
+	g.out = append(g.out, s)
+}
@@ -0,0 +1,348 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"thermal-throttling-analyzer/internal/sensors"
+)
+
+// Kind is a segment type.
+type Kind string
+
+const (
+	KindPlateau  Kind = "plateau"   // Hold the current values
+	KindRamp     Kind = "ramp"      // Move the temperature linearly to To
+	KindSpike    Kind = "spike"     // Raise the temperature by Delta, then drop back
+	KindNoise    Kind = "noise"     // Jitter the temperature by up to ±Amplitude
+	KindFreqSag  Kind = "freq_sag"  // Lower the clock by Percent, then restore it
+	KindDropout  Kind = "dropout"   // Report Signals as missing
+	KindLoadStep Kind = "load_step" // Change the load (and optionally the clock) for good
+)
+
+// Segment is one step of a scenario. Segments run one after another, each for
+// Duration; only the fields of its Kind are used.
+type Segment struct {
+	Kind      Kind
+	Duration  time.Duration
+	To        float64  // ramp: target temperature (°C)
+	Delta     float64  // spike: temperature jump (°C)
+	Amplitude float64  // noise: maximum deviation (°C)
+	Percent   float64  // freq_sag: clock drop (%)
+	Signals   []string // dropout: signals to drop; defaults to the temperature
+	Load      float64  // load_step: new load (%)
+	FreqMHz   int      // load_step: new clock, 0 keeps the current one
+}
+
+// Start holds the values a scenario begins with.
+type Start struct {
+	TempC       float64
+	FreqMHz     int
+	BaseFreqMHz int
+	LoadPercent float64
+}
+
+// Scenario is a synthetic thermal workload. Generate turns it into snapshots.
+type Scenario struct {
+	Name        string
+	Description string
+	Interval    time.Duration // Time between snapshots
+	Seed        int64         // Seeds the noise, so every run is identical
+	Start       Start
+	Segments    []Segment
+	// Filter holds the filter chains the scenario is checked with, by signal
+	// (see filter.ParseChain). Signals without one are not filtered.
+	Filter map[string]string
+	// Expect is the sequence of states the state machine should go through,
+	// with repeats collapsed. Empty means no expectation.
+	Expect []string
+}
+
+// Load reads a scenario file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	sc, err := Parse(string(data), name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return sc, nil
+}
+
+// Parse decodes a scenario. name is used when the document has no name of its own.
+func Parse(text, name string) (*Scenario, error) {
+	doc, err := parseYAML(text)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &Scenario{
+		Name:     name,
+		Interval: 2 * time.Second,
+		Seed:     1,
+		Start:    Start{TempC: 45, FreqMHz: 3000, BaseFreqMHz: 3000, LoadPercent: 10},
+	}
+	d := decoder{where: "scenario"}
+	d.str(doc, "name", &sc.Name)
+	d.str(doc, "description", &sc.Description)
+	d.duration(doc, "interval", &sc.Interval)
+	d.int64(doc, "seed", &sc.Seed)
+	if start, ok := d.mapping(doc, "start"); ok {
+		sd := decoder{where: "start"}
+		sd.float(start, "temp", &sc.Start.TempC)
+		sd.int(start, "freq", &sc.Start.FreqMHz)
+		sd.int(start, "base_freq", &sc.Start.BaseFreqMHz)
+		sd.float(start, "load", &sc.Start.LoadPercent)
+		sd.unknown(start, "temp", "freq", "base_freq", "load")
+		if sd.err != nil {
+			return nil, sd.err
+		}
+	}
+	if chains, ok := d.mapping(doc, "filter"); ok {
+		fd := decoder{where: "filter"}
+		sc.Filter = map[string]string{}
+		for signal := range chains {
+			var spec string
+			fd.str(chains, signal, &spec)
+			sc.Filter[signal] = spec
+		}
+		if fd.err != nil {
+			return nil, fd.err
+		}
+		if _, err := sc.Pipeline(); err != nil {
+			return nil, err
+		}
+	}
+	d.strings(doc, "expect", &sc.Expect)
+	d.unknown(doc, "name", "description", "interval", "seed", "start", "segments", "filter", "expect")
+	if d.err != nil {
+		return nil, d.err
+	}
+	if sc.Interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+
+	rawSegments, ok := doc["segments"].([]any)
+	if !ok || len(rawSegments) == 0 {
+		return nil, fmt.Errorf("scenario needs a list of segments")
+	}
+	for i, raw := range rawSegments {
+		seg, err := parseSegment(raw)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: %v", i+1, err)
+		}
+		sc.Segments = append(sc.Segments, seg)
+	}
+	return sc, nil
+}
+
+func parseSegment(raw any) (Segment, error) {
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return Segment{}, fmt.Errorf("expected a map with a type")
+	}
+	var seg Segment
+	d := decoder{where: "segment"}
+	var kind string
+	d.str(m, "type", &kind)
+	seg.Kind = Kind(kind)
+	d.duration(m, "duration", &seg.Duration)
+
+	switch seg.Kind {
+	case KindPlateau:
+		d.unknown(m, "type", "duration")
+	case KindRamp:
+		d.require(m, "to")
+		d.float(m, "to", &seg.To)
+		d.unknown(m, "type", "duration", "to")
+	case KindSpike:
+		d.require(m, "delta")
+		d.float(m, "delta", &seg.Delta)
+		d.unknown(m, "type", "duration", "delta")
+	case KindNoise:
+		d.require(m, "amplitude")
+		d.float(m, "amplitude", &seg.Amplitude)
+		d.unknown(m, "type", "duration", "amplitude")
+	case KindFreqSag:
+		d.require(m, "percent")
+		d.float(m, "percent", &seg.Percent)
+		d.unknown(m, "type", "duration", "percent")
+	case KindDropout:
+		d.strings(m, "signals", &seg.Signals)
+		d.unknown(m, "type", "duration", "signals")
+		if len(seg.Signals) == 0 {
+			seg.Signals = []string{sensors.SignalTemp}
+		}
+		for i, name := range seg.Signals {
+			signal, ok := signalNames[name]
+			if !ok {
+				return Segment{}, fmt.Errorf("unknown signal %q", name)
+			}
+			seg.Signals[i] = signal
+		}
+	case KindLoadStep:
+		d.require(m, "load")
+		d.float(m, "load", &seg.Load)
+		d.int(m, "freq", &seg.FreqMHz)
+		d.unknown(m, "type", "duration", "load", "freq")
+	case "":
+		return Segment{}, fmt.Errorf("missing type")
+	default:
+		return Segment{}, fmt.Errorf("unknown segment type %q", kind)
+	}
+	if d.err != nil {
+		return Segment{}, d.err
+	}
+	if seg.Duration <= 0 && seg.Kind != KindLoadStep {
+		return Segment{}, fmt.Errorf("%s needs a positive duration", seg.Kind)
+	}
+	return seg, nil
+}
+
+// signalNames maps the names a dropout may use to snapshot signals.
+var signalNames = map[string]string{
+	"temp":                 sensors.SignalTemp,
+	"freq":                 sensors.SignalFreq,
+	"base_freq":            sensors.SignalBaseFreq,
+	"load":                 sensors.SignalLoad,
+	sensors.SignalTemp:     sensors.SignalTemp,
+	sensors.SignalFreq:     sensors.SignalFreq,
+	sensors.SignalBaseFreq: sensors.SignalBaseFreq,
+	sensors.SignalLoad:     sensors.SignalLoad,
+}
+
+// decoder pulls typed fields out of a parsed map and keeps the first error.
+type decoder struct {
+	where string
+	err   error
+}
+
+func (d *decoder) fail(key string, err error) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%s: %s: %v", d.where, key, err)
+	}
+}
+
+func (d *decoder) scalar(m map[string]any, key string) (string, bool) {
+	v, ok := m[key]
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	if !ok {
+		d.fail(key, fmt.Errorf("expected a single value"))
+		return "", false
+	}
+	return s, true
+}
+
+func (d *decoder) require(m map[string]any, key string) {
+	if _, ok := m[key]; !ok {
+		d.fail(key, fmt.Errorf("required"))
+	}
+}
+
+func (d *decoder) unknown(m map[string]any, known ...string) {
+	for key := range m {
+		found := false
+		for _, k := range known {
+			if key == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			d.fail(key, fmt.Errorf("unknown field"))
+		}
+	}
+}
+
+func (d *decoder) str(m map[string]any, key string, dst *string) {
+	if s, ok := d.scalar(m, key); ok {
+		*dst = s
+	}
+}
+
+func (d *decoder) float(m map[string]any, key string, dst *float64) {
+	if s, ok := d.scalar(m, key); ok {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			d.fail(key, err)
+			return
+		}
+		*dst = v
+	}
+}
+
+func (d *decoder) int(m map[string]any, key string, dst *int) {
+	if s, ok := d.scalar(m, key); ok {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			d.fail(key, err)
+			return
+		}
+		*dst = v
+	}
+}
+
+func (d *decoder) int64(m map[string]any, key string, dst *int64) {
+	if s, ok := d.scalar(m, key); ok {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			d.fail(key, err)
+			return
+		}
+		*dst = v
+	}
+}
+
+func (d *decoder) duration(m map[string]any, key string, dst *time.Duration) {
+	if s, ok := d.scalar(m, key); ok {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			d.fail(key, err)
+			return
+		}
+		*dst = v
+	}
+}
+
+func (d *decoder) mapping(m map[string]any, key string) (map[string]any, bool) {
+	v, ok := m[key]
+	if !ok {
+		return nil, false
+	}
+	sub, ok := v.(map[string]any)
+	if !ok {
+		d.fail(key, fmt.Errorf("expected a map"))
+		return nil, false
+	}
+	return sub, true
+}
+
+func (d *decoder) strings(m map[string]any, key string, dst *[]string) {
+	v, ok := m[key]
+	if !ok {
+		return
+	}
+	list, ok := v.([]any)
+	if !ok {
+		d.fail(key, fmt.Errorf("expected a list"))
+		return
+	}
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			d.fail(key, fmt.Errorf("expected a list of values"))
+			return
+		}
+		*dst = append(*dst, s)
+	}
+}
@@ -0,0 +1,261 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The scenario format is a small YAML subset, parsed here to avoid a dependency:
+// block maps and lists by indentation, "- key: value" list items, flow lists
+// ([a, b]) and flow maps ({k: v}), quoted or plain scalars and # comments.
+// Every scalar is kept as a string; the scenario decoder converts it.
+
+type yamlLine struct {
+	num     int
+	indent  int
+	content string
+}
+
+// parseYAML parses a document whose top level is a map.
+func parseYAML(text string) (map[string]any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimRight(stripComment(raw), " \t\r")
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		if strings.Contains(raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))], "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		content := strings.TrimLeft(raw, " ")
+		lines = append(lines, yamlLine{num: i + 1, indent: len(raw) - len(content), content: content})
+	}
+	if len(lines) == 0 {
+		return map[string]any{}, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	v, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("top level must be a map")
+	}
+	return m, nil
+}
+
+// stripComment drops a # comment that is not inside quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block parses the map or list starting at the current line, which has the given indent.
+func (p *yamlParser) block(indent int) (any, error) {
+	if isListItem(p.lines[p.pos].content) {
+		return p.list(indent)
+	}
+	return p.mapping(indent)
+}
+
+func isListItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+func (p *yamlParser) list(indent int) ([]any, error) {
+	var items []any
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		// A list can be the value of a key at its own indent; the next key ends it.
+		if line.indent < indent || (line.indent == indent && !isListItem(line.content)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: expected a list item", line.num)
+		}
+
+		rest := strings.TrimSpace(strings.TrimPrefix(line.content, "-"))
+		switch {
+		case rest == "":
+			// Item is the nested block on the following lines.
+			p.pos++
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+				items = append(items, "")
+				continue
+			}
+			v, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		case isKey(rest):
+			// "- key: value": a map whose keys line up with the first one.
+			childIndent := line.indent + len(line.content) - len(rest)
+			p.lines[p.pos] = yamlLine{num: line.num, indent: childIndent, content: rest}
+			v, err := p.mapping(childIndent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		default:
+			v, err := parseFlow(rest, line.num)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	m := map[string]any{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+		if !isKey(line.content) {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.num)
+		}
+		key, value, _ := strings.Cut(line.content, ":")
+		key = unquote(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.num, key)
+		}
+		p.pos++
+
+		if value != "" {
+			v, err := parseFlow(value, line.num)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			continue
+		}
+		// Nested block: deeper indented, or a list at the same indent.
+		if p.pos < len(p.lines) {
+			next := p.lines[p.pos]
+			if next.indent > indent || (next.indent == indent && isListItem(next.content)) {
+				v, err := p.block(next.indent)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = v
+				continue
+			}
+		}
+		m[key] = ""
+	}
+	return m, nil
+}
+
+// isKey reports whether content starts a "key: value" pair.
+func isKey(content string) bool {
+	if strings.HasPrefix(content, "[") || strings.HasPrefix(content, "{") ||
+		strings.HasPrefix(content, "\"") || strings.HasPrefix(content, "'") {
+		return false
+	}
+	i := strings.Index(content, ":")
+	return i > 0 && (i == len(content)-1 || content[i+1] == ' ')
+}
+
+// parseFlow parses an inline value: [a, b], {k: v} or a scalar.
+func parseFlow(s string, num int) (any, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("line %d: unterminated list", num)
+		}
+		var items []any
+		for _, part := range splitFlow(s[1 : len(s)-1]) {
+			v, err := parseFlow(part, num)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case strings.HasPrefix(s, "{"):
+		if !strings.HasSuffix(s, "}") {
+			return nil, fmt.Errorf("line %d: unterminated map", num)
+		}
+		m := map[string]any{}
+		for _, part := range splitFlow(s[1 : len(s)-1]) {
+			key, value, ok := strings.Cut(part, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected \"key: value\" in %q", num, part)
+			}
+			v, err := parseFlow(value, num)
+			if err != nil {
+				return nil, err
+			}
+			m[unquote(strings.TrimSpace(key))] = v
+		}
+		return m, nil
+	}
+	return unquote(s), nil
+}
+
+// splitFlow splits the inside of a flow collection on top-level commas.
+func splitFlow(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(parts) > 0 {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,109 @@
+package sensors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCgroupTotals(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string // CgroupCPU.Path
+		files map[string]string
+		want  CgroupData
+	}{
+		{
+			name: "v2",
+			files: map[string]string{
+				"proc/self/cgroup": "0::/user.slice/app.scope",
+				"cgroup/user.slice/app.scope/cpu.stat": `usage_usec 9000000
+nr_periods 120
+nr_throttled 30
+throttled_usec 450000`,
+			},
+			want: CgroupData{Path: "/user.slice/app.scope", Periods: 120, ThrottledPeriods: 30, ThrottledUsec: 450000},
+		},
+		{
+			name: "v1 co-mounted with cpuacct",
+			files: map[string]string{
+				"proc/self/cgroup": "5:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc",
+				"cgroup/cpu,cpuacct/docker/abc/cpu.stat": `nr_periods 80
+nr_throttled 8
+throttled_time 2500000000`,
+			},
+			want: CgroupData{Path: "/docker/abc", Periods: 80, ThrottledPeriods: 8, ThrottledUsec: 2500000},
+		},
+		{
+			// systemd's hybrid layout: the unified hierarchy exists, but the
+			// cpu controller is still on v1.
+			name: "hybrid",
+			files: map[string]string{
+				"proc/self/cgroup":                        "4:cpu,cpuacct:/system.slice/db.service\n0::/system.slice/db.service",
+				"cgroup/system.slice/db.service/cpu.stat": "usage_usec 9000000\nuser_usec 6000000\nsystem_usec 3000000",
+				"cgroup/cpu,cpuacct/system.slice/db.service/cpu.stat": `nr_periods 50
+nr_throttled 5
+throttled_time 1000000`,
+			},
+			want: CgroupData{Path: "/system.slice/db.service", Periods: 50, ThrottledPeriods: 5, ThrottledUsec: 1000},
+		},
+		{
+			name: "explicit path",
+			path: "/batch",
+			files: map[string]string{
+				"proc/self/cgroup":      "0::/user.slice",
+				"cgroup/batch/cpu.stat": "nr_periods 10\nnr_throttled 10\nthrottled_usec 99",
+			},
+			want: CgroupData{Path: "/batch", Periods: 10, ThrottledPeriods: 10, ThrottledUsec: 99},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeTree(t, tt.files)
+			c := &CgroupCPU{Root: filepath.Join(root, "cgroup"), ProcRoot: filepath.Join(root, "proc"), Path: tt.path}
+			got, err := c.Totals()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Totals() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCgroupTotalsWithoutCPUController(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"proc/self/cgroup":           "0::/user.slice",
+		"cgroup/user.slice/cpu.stat": "usage_usec 9000000",
+	})
+	c := &CgroupCPU{Root: filepath.Join(root, "cgroup"), ProcRoot: filepath.Join(root, "proc")}
+	if _, err := c.Totals(); err == nil || !strings.Contains(err.Error(), "no quota accounting") {
+		t.Errorf("error = %v, want one about missing quota accounting", err)
+	}
+}
+
+func TestCgroupSample(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"cpu.stat": "nr_periods 100\nnr_throttled 10\nthrottled_usec 5000",
+	})
+	c := &CgroupCPU{Root: root, Path: "/"}
+	if _, err := c.Sample(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stat := "nr_periods 140\nnr_throttled 25\nthrottled_usec 8000\n"
+	if err := os.WriteFile(filepath.Join(root, "cpu.stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CgroupData{Path: "/", Periods: 40, ThrottledPeriods: 15, ThrottledUsec: 3000}
+	if got != want {
+		t.Errorf("Sample() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package sensors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// processorQuery fetches every Win32_Processor instance in one CIM round trip.
+// ConvertTo-Json emits numbers in invariant form regardless of the user's locale,
+// and returns a bare object on single-socket machines and an array otherwise.
+const processorQuery = "Get-CimInstance -ClassName Win32_Processor | " +
+	"Select-Object Name,NumberOfCores,CurrentClockSpeed,MaxClockSpeed,LoadPercentage | " +
+	"ConvertTo-Json -Compress"
+
+// processorCacheTTL lets Frequency and Load, which run in parallel within one tick,
+// share a single query result.
+const processorCacheTTL = time.Second
+
+// win32Processor mirrors the fields selected from one Win32_Processor instance.
+type win32Processor struct {
+	Name              string
+	NumberOfCores     cimNumber
+	CurrentClockSpeed cimNumber
+	MaxClockSpeed     cimNumber
+	LoadPercentage    cimNumber
+}
+
+// cimNumber decodes a CIM numeric property. WMI returns null for values it has
+// no sample for (LoadPercentage right after boot), and some providers return
+// numbers as strings, possibly with a locale decimal comma.
+type cimNumber struct {
+	Value float64
+	Valid bool
+}
+
+func (n *cimNumber) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*n = cimNumber{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		v, err := parseCIMFloat(s)
+		if err != nil {
+			return err
+		}
+		*n = cimNumber{Value: v, Valid: true}
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return err
+	}
+	*n = cimNumber{Value: v, Valid: true}
+	return nil
+}
+
+// parseCIMFloat parses a number formatted for the user's locale: "2394,5",
+// "1.234,5", "1,234.5" or "1 234,5". When both separators appear, the last one
+// is the decimal separator; a lone comma is taken as decimal, and a separator
+// that repeats groups thousands.
+func parseCIMFloat(s string) (float64, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\'':
+			// Thousands separators of fr-FR, de-CH, ...
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+
+	comma, dot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case comma >= 0 && dot >= 0:
+		if comma > dot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case comma >= 0:
+		if strings.Count(s, ",") > 1 {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	case strings.Count(s, ".") > 1:
+		s = strings.ReplaceAll(s, ".", "")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseProcessors decodes processorQuery output, accepting both a single object and an array.
+func parseProcessors(output []byte) ([]win32Processor, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, fmt.Errorf("no processor data returned")
+	}
+
+	var procs []win32Processor
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &procs); err != nil {
+			return nil, fmt.Errorf("failed to parse processor data: %v", err)
+		}
+	} else {
+		var proc win32Processor
+		if err := json.Unmarshal(output, &proc); err != nil {
+			return nil, fmt.Errorf("failed to parse processor data: %v", err)
+		}
+		procs = append(procs, proc)
+	}
+
+	if len(procs) == 0 {
+		return nil, fmt.Errorf("no processor data returned")
+	}
+	return procs, nil
+}
+
+// processorCache holds the most recent processorQuery result.
+type processorCache struct {
+	mu      sync.Mutex
+	fetched time.Time
+	procs   []win32Processor
+}
+
+// get returns cached processors if fresh, otherwise runs the query.
+// Concurrent callers wait on the mutex and reuse the first caller's result.
+func (c *processorCache) get(ctx context.Context) ([]win32Processor, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.procs != nil && time.Since(c.fetched) < processorCacheTTL {
+		return c.procs, nil
+	}
+
+	output, err := execPowerShell(ctx, processorQuery)
+	if err != nil {
+		return nil, err
+	}
+	procs, err := parseProcessors([]byte(output))
+	if err != nil {
+		return nil, parseError(processorQuery, err)
+	}
+
+	c.procs = procs
+	c.fetched = time.Now()
+	return procs, nil
+}
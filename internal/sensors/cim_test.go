@@ -0,0 +1,111 @@
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFixture returns a captured command output from testdata.
+func readFixture(t *testing.T, dir, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseCIMFloat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"2394", 2394},
+		{"2394.5", 2394.5},
+		{"2394,5", 2394.5},
+		{"1.234,5", 1234.5},
+		{"1,234.5", 1234.5},
+		{"1 234,5", 1234.5},
+		{"1\u00a0234,5", 1234.5},
+		{"1'234.5", 1234.5},
+		{"1.234.567", 1234567},
+		{"1,234,567", 1234567},
+		{" 42 ", 42},
+	}
+	for _, tt := range tests {
+		got, err := parseCIMFloat(tt.in)
+		if err != nil {
+			t.Errorf("parseCIMFloat(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCIMFloat(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "n/a", "1,2,3.4.5"} {
+		if _, err := parseCIMFloat(in); err == nil {
+			t.Errorf("parseCIMFloat(%q) succeeded", in)
+		}
+	}
+}
+
+func TestParseProcessorsSingleObject(t *testing.T) {
+	// ConvertTo-Json returns a bare object on a single-socket machine.
+	procs, err := parseProcessors(readFixture(t, "wmi-laptop", "win32_processor.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(procs) != 1 {
+		t.Fatalf("got %d processors, want 1", len(procs))
+	}
+	p := procs[0]
+	if p.NumberOfCores.Value != 6 || p.CurrentClockSpeed.Value != 2592 || p.LoadPercentage.Value != 17 {
+		t.Errorf("processor = %+v", p)
+	}
+
+	freq, err := frequencyFromProcessors(procs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if freq.CurrentMHz != 2592 || freq.BaseMHz != 2592 || len(freq.Sockets) != 1 {
+		t.Errorf("frequency = %+v", freq)
+	}
+}
+
+func TestParseProcessorsArray(t *testing.T) {
+	// Two sockets: an array, with a locale-formatted string and a null.
+	procs, err := parseProcessors(readFixture(t, "wmi-dual-socket", "win32_processor.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(procs) != 2 {
+		t.Fatalf("got %d processors, want 2", len(procs))
+	}
+	if !procs[1].CurrentClockSpeed.Valid || procs[1].CurrentClockSpeed.Value != 2394.5 {
+		t.Errorf("second socket clock = %+v, want 2394.5", procs[1].CurrentClockSpeed)
+	}
+	if procs[1].LoadPercentage.Valid {
+		t.Errorf("null LoadPercentage decoded as %+v", procs[1].LoadPercentage)
+	}
+
+	freq, err := frequencyFromProcessors(procs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Equal core counts: the plain average of both sockets.
+	if freq.CurrentMHz != 2693 || freq.MinCurrentMHz != 2394 || freq.MaxCurrentMHz != 2993 {
+		t.Errorf("frequency = %+v", freq)
+	}
+	if len(freq.Sockets) != 2 || freq.Sockets[0].Cores != 24 {
+		t.Errorf("sockets = %+v", freq.Sockets)
+	}
+}
+
+func TestParseProcessorsInvalid(t *testing.T) {
+	for _, in := range []string{"", "[]", "{not json", `{"CurrentClockSpeed":"fast"}`} {
+		if _, err := parseProcessors([]byte(in)); err == nil {
+			t.Errorf("parseProcessors(%q) succeeded", in)
+		}
+	}
+}
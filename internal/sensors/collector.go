@@ -1,42 +1,230 @@
 package sensors
 
 import (
-	"math/rand"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 )
 
-// CollectSnapshot gathers data from all sensors and returns a unified Snapshot.
-func CollectSnapshot() *Snapshot {
+// DefaultOversampleSpacing separates repeated reads of an oversampled signal,
+// so they are not all served from the same sensor update.
+const DefaultOversampleSpacing = 20 * time.Millisecond
+
+// DefaultQueryTimeout bounds a single provider read. It is independent of the
+// per-tick deadline: a read that misses its tick keeps running up to this long
+// so its result can still be reused (as stale) by the next tick.
+const DefaultQueryTimeout = 5 * time.Second
+
+// query identifies one provider read. Frequency fills two signals.
+type query int
+
+const (
+	queryTemp query = iota
+	queryFreq
+	queryLoad
+	queryPower
+	queryThrottle
+	queryMSR
+	queryQuota
+	queryPressure
+	queryZones
+	queryFans
+	queryPowerSupply
+	queryDeviceTemps
+)
+
+// coreQueries are answered by every Provider; the rest depend on optional interfaces.
+var coreQueries = []query{queryTemp, queryFreq, queryLoad}
+
+// queriesFor lists the reads p supports.
+func queriesFor(p Provider) []query {
+	queries := append([]query{}, coreQueries...)
+	if _, ok := p.(PowerProvider); ok {
+		queries = append(queries, queryPower)
+	}
+	if _, ok := p.(ThrottleProvider); ok {
+		queries = append(queries, queryThrottle)
+	}
+	if _, ok := p.(MSRProvider); ok {
+		queries = append(queries, queryMSR)
+	}
+	if _, ok := p.(QuotaProvider); ok {
+		queries = append(queries, queryQuota)
+	}
+	if _, ok := p.(PressureProvider); ok {
+		queries = append(queries, queryPressure)
+	}
+	if _, ok := p.(ZoneProvider); ok {
+		queries = append(queries, queryZones)
+	}
+	if _, ok := p.(FanProvider); ok {
+		queries = append(queries, queryFans)
+	}
+	if _, ok := p.(PowerSupplyProvider); ok {
+		queries = append(queries, queryPowerSupply)
+	}
+	if _, ok := p.(DeviceTempProvider); ok {
+		queries = append(queries, queryDeviceTemps)
+	}
+	return queries
+}
+
+// reading is the outcome of one provider read.
+type reading struct {
+	query    query
+	temp     float64
+	tempFrom []string // Sensors a TempMapping took temp from
+	freq     FrequencyData
+	load     LoadData
+	power    PowerData
+	throttle ThrottleData
+	msr      MSRData
+	quota    CgroupData
+	pressure PressureData
+	zones    ZoneData
+	fans     FanData
+	supply   PowerSupplyData
+	devices  DeviceTempData
+	err      error
+}
+
+// Collector queries a provider's signals in parallel, bounded by the caller's context.
+// A signal whose read does not finish before the deadline is filled from the last
+// successful read and marked stale; the late read is not restarted until it completes,
+// so slow backends cannot pile up goroutines or processes. Counter deltas from a late
+// read are held back and added to the next fresh read of the same signal.
+type Collector struct {
+	// Provider is the backend to read. When nil, the registry's ActiveProvider is used.
+	Provider     Provider
+	QueryTimeout time.Duration
+	// Oversample reads a signal this many times per collection and keeps the
+	// median. Supported for SignalTemp and SignalFreq.
+	Oversample        map[string]int
+	OversampleSpacing time.Duration
+	// TempMapping, when set, takes the temperature from the named sensors
+	// instead of the backend's own choice.
+	TempMapping *TempMapping
+
+	mu       sync.Mutex
+	backend  string
+	inflight map[query]bool
+	latest   map[query]reading
+	pending  map[query]reading // counter deltas not yet reported
+}
+
+// NewCollector returns a collector that follows the registry's active backend.
+func NewCollector() *Collector {
+	return &Collector{
+		QueryTimeout:      DefaultQueryTimeout,
+		Oversample:        map[string]int{},
+		OversampleSpacing: DefaultOversampleSpacing,
+	}
+}
+
+var defaultCollector = NewCollector()
+
+// CollectSnapshot gathers data from the active sensor backend and returns a unified Snapshot.
+// Signals the backend cannot read are recorded as missing; nothing is made up.
+func CollectSnapshot(ctx context.Context) *Snapshot {
+	return defaultCollector.Collect(ctx)
+}
+
+// SetOversample makes CollectSnapshot read signal n times per snapshot and keep
+// the median. Call it before collection starts.
+func SetOversample(signal string, n int) error {
+	if signal != SignalTemp && signal != SignalFreq {
+		return fmt.Errorf("oversampling is only supported for %s and %s", SignalTemp, SignalFreq)
+	}
+	defaultCollector.Oversample[signal] = n
+	return nil
+}
+
+// SetTempMapping makes CollectSnapshot take the CPU temperature from the sensors
+// m names; nil restores the backend's automatic choice. Call it before
+// collection starts.
+func SetTempMapping(m *TempMapping) {
+	defaultCollector.TempMapping = m
+}
+
+// TempMappingInUse returns the mapping set with SetTempMapping, or nil.
+func TempMappingInUse() *TempMapping {
+	return defaultCollector.TempMapping
+}
+
+// Collect reads every signal concurrently and returns once all reads finished
+// or ctx is done, whichever comes first.
+func (c *Collector) Collect(ctx context.Context) *Snapshot {
 	s := &Snapshot{
 		Timestamp:    time.Now(),
 		ValidSignals: []string{},
+		Sources:      map[string]SignalSource{},
 	}
 
-	// 1. Temperature
-	temp, err := GetCPUTemperature()
-	if err == nil {
-		s.TempC = temp
-		s.ValidSignals = append(s.ValidSignals, "TempC")
-	} else {
-		// Mock data: Generate random temperature between 45.0 and 65.0
-		// This ensures the watch command shows a realistic "normal" range instead of 0.
-		s.TempC = 45.0 + rand.Float64()*20.0
-		s.ValidSignals = append(s.ValidSignals, "TempC")
+	p := c.Provider
+	if p == nil {
+		var err error
+		p, err = ActiveProvider()
+		if err != nil {
+			// No backend at all: upstream treats an empty ValidSignals as "no sensors".
+			for _, q := range coreQueries {
+				s.apply(reading{query: q, err: err}, ProvenanceMissing, "")
+			}
+			return s
+		}
 	}
 
-	// 2. Frequency
-	freq, err := GetCPUFrequency()
-	if err == nil {
-		s.FreqMHz = freq.CurrentMHz
-		s.BaseFreqMHz = freq.BaseMHz
-		s.ValidSignals = append(s.ValidSignals, "FreqMHz", "BaseFreqMHz")
+	backend := p.Name()
+	measured := ProvenanceMeasured
+	if sim, ok := p.(simulator); ok && sim.Simulated() {
+		measured = ProvenanceMocked
 	}
 
-	// 3. Load
-	load, err := GetCPULoad()
-	if err == nil {
-		s.LoadPercent = load
-		s.ValidSignals = append(s.ValidSignals, "LoadPercent")
+	queries := queriesFor(p)
+	results := make(chan reading, len(queries))
+	started := 0
+	for _, q := range queries {
+		if !c.begin(backend, q) {
+			// Previous read of this signal is still running; don't stack another.
+			continue
+		}
+		started++
+		go func(q query) {
+			// Detach from the tick deadline so a late result still lands in the cache.
+			qctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.QueryTimeout)
+			defer cancel()
+			r := c.read(qctx, p, q)
+			c.finish(backend, r)
+			results <- r
+		}(q)
+	}
+
+	got := make(map[query]reading, len(queries))
+wait:
+	for ; started > 0; started-- {
+		select {
+		case r := <-results:
+			got[r.query] = r
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	for _, q := range queries {
+		if r, ok := got[q]; ok {
+			if r.err != nil {
+				s.apply(r, ProvenanceMissing, backend)
+			} else {
+				s.apply(c.claim(r), measured, backend)
+			}
+			continue
+		}
+		if r, ok := c.last(q); ok {
+			s.apply(r, ProvenanceStale, backend)
+		} else {
+			s.apply(reading{query: q, err: context.DeadlineExceeded}, ProvenanceMissing, backend)
+		}
 	}
 
 	// Optional: If absolutely NO signals are valid, we might return a special error state or just the empty snapshot.
@@ -44,3 +232,323 @@ func CollectSnapshot() *Snapshot {
 
 	return s
 }
+
+// begin marks q as in flight, unless it already is. Switching backends drops
+// everything cached from the previous one.
+func (c *Collector) begin(backend string, q query) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.backend != backend || c.inflight == nil {
+		c.backend = backend
+		c.inflight = make(map[query]bool)
+		c.latest = make(map[query]reading)
+		c.pending = make(map[query]reading)
+	}
+	if c.inflight[q] {
+		return false
+	}
+	c.inflight[q] = true
+	return true
+}
+
+// finish clears the in-flight mark and caches successful reads. Counter deltas
+// are also added up in pending until a collection claims them.
+func (c *Collector) finish(backend string, r reading) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.backend != backend {
+		return
+	}
+	c.inflight[r.query] = false
+	if r.err != nil {
+		return
+	}
+	c.latest[r.query] = r
+	if r.query == queryThrottle || r.query == queryQuota {
+		if p, ok := c.pending[r.query]; ok {
+			r = p.plus(r)
+		}
+		c.pending[r.query] = r
+	}
+}
+
+// claim returns the counter deltas pending for r's signal, which include r's
+// own, and starts a new count. Other readings are returned unchanged.
+func (c *Collector) claim(r reading) reading {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[r.query]
+	if !ok {
+		return r
+	}
+	delete(c.pending, r.query)
+	return p
+}
+
+// plus returns next with the counter deltas of r added to it.
+func (r reading) plus(next reading) reading {
+	switch r.query {
+	case queryThrottle:
+		next.throttle.CoreEvents += r.throttle.CoreEvents
+		next.throttle.PackageEvents += r.throttle.PackageEvents
+		next.throttle.CoreTimeMs += r.throttle.CoreTimeMs
+		next.throttle.PackageTimeMs += r.throttle.PackageTimeMs
+	case queryQuota:
+		next.quota.Periods += r.quota.Periods
+		next.quota.ThrottledPeriods += r.quota.ThrottledPeriods
+		next.quota.ThrottledUsec += r.quota.ThrottledUsec
+	}
+	return next
+}
+
+func (c *Collector) last(q query) (reading, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.latest[q]
+	return r, ok
+}
+
+// read performs one query, oversampled if configured.
+func (c *Collector) read(ctx context.Context, p Provider, q query) reading {
+	n := 1
+	switch q {
+	case queryTemp:
+		n = c.Oversample[SignalTemp]
+	case queryFreq:
+		n = c.Oversample[SignalFreq]
+	}
+	if n <= 1 {
+		return c.readOnce(ctx, p, q)
+	}
+
+	var samples []reading
+	last := reading{query: q}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(c.OversampleSpacing):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		last = c.readOnce(ctx, p, q)
+		if last.err == nil {
+			samples = append(samples, last)
+		}
+	}
+	if len(samples) == 0 {
+		if last.err == nil {
+			last.err = ctx.Err()
+		}
+		return last
+	}
+	return medianReading(samples)
+}
+
+// medianReading returns the sample with the median value. The whole sample is
+// kept, so a frequency reading's per-core data stays consistent.
+func medianReading(samples []reading) reading {
+	value := func(r reading) float64 {
+		if r.query == queryFreq {
+			return float64(r.freq.CurrentMHz)
+		}
+		return r.temp
+	}
+	sort.Slice(samples, func(i, j int) bool { return value(samples[i]) < value(samples[j]) })
+	return samples[len(samples)/2]
+}
+
+func (c *Collector) readOnce(ctx context.Context, p Provider, q query) reading {
+	r := reading{query: q}
+	switch q {
+	case queryTemp:
+		if c.TempMapping != nil {
+			r.temp, r.tempFrom, r.err = mappedTemperature(ctx, p, c.TempMapping)
+		} else {
+			r.temp, r.err = p.Temperature(ctx)
+		}
+	case queryFreq:
+		r.freq, r.err = p.Frequency(ctx)
+	case queryLoad:
+		r.load, r.err = p.Load(ctx)
+	case queryPower:
+		r.power, r.err = p.(PowerProvider).Power(ctx)
+	case queryThrottle:
+		r.throttle, r.err = p.(ThrottleProvider).ThrottleCounters(ctx)
+	case queryMSR:
+		r.msr, r.err = p.(MSRProvider).ThermStatus(ctx)
+	case queryQuota:
+		r.quota, r.err = p.(QuotaProvider).CgroupThrottle(ctx)
+	case queryPressure:
+		r.pressure, r.err = p.(PressureProvider).Pressure(ctx)
+	case queryZones:
+		r.zones, r.err = p.(ZoneProvider).ThermalZones(ctx)
+	case queryFans:
+		r.fans, r.err = p.(FanProvider).Fans(ctx)
+	case queryPowerSupply:
+		r.supply, r.err = p.(PowerSupplyProvider).PowerSupply(ctx)
+	case queryDeviceTemps:
+		r.devices, r.err = p.(DeviceTempProvider).DeviceTemps(ctx)
+	}
+	return r
+}
+
+// mappedTemperature reads the temperature from the sensors m names.
+func mappedTemperature(ctx context.Context, p Provider, m *TempMapping) (float64, []string, error) {
+	tp, ok := p.(TempSensorProvider)
+	if !ok {
+		return 0, nil, fmt.Errorf("backend %s cannot select temperature sensors", p.Name())
+	}
+	list, err := tp.TempSensors(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	return m.Apply(list)
+}
+
+// apply copies a reading into the snapshot and records its provenance.
+// A reading with an error only records the signals as missing.
+func (s *Snapshot) apply(r reading, prov Provenance, backend string) {
+	if r.err != nil {
+		prov = ProvenanceMissing
+	}
+
+	switch r.query {
+	case queryTemp:
+		// 1. Temperature
+		if prov != ProvenanceMissing {
+			s.TempC = r.temp
+			s.TempSensors = r.tempFrom
+		}
+		s.SetSource(SignalTemp, prov, backend)
+
+	case queryFreq:
+		// 2. Frequency
+		if prov != ProvenanceMissing {
+			s.FreqMHz = r.freq.CurrentMHz
+			s.FreqMinMHz = r.freq.MinCurrentMHz
+			s.FreqMaxMHz = r.freq.MaxCurrentMHz
+			s.MaxFreqMHz = r.freq.MaxMHz
+			s.PerCoreFreqMHz = r.freq.PerCoreMHz
+			s.BaseFreqMHz = r.freq.BaseMHz
+		}
+		s.SetSource(SignalFreq, prov, backend)
+		if r.freq.BaseMHz == 0 && prov != ProvenanceMissing {
+			// The clocks were read, but the backend knows no base clock.
+			s.SetSource(SignalBaseFreq, ProvenanceMissing, backend)
+		} else {
+			s.SetSource(SignalBaseFreq, prov, backend)
+		}
+
+	case queryLoad:
+		// 3. Load
+		if prov != ProvenanceMissing {
+			s.LoadPercent = r.load.Percent
+			s.PerCPULoad = r.load.PerCPU
+			s.IOWaitPercent = r.load.IOWaitPercent
+			s.StealPercent = r.load.StealPercent
+			s.IRQPercent = r.load.IRQPercent
+		}
+		s.SetSource(SignalLoad, prov, backend)
+
+	case queryPower:
+		// 4. Power
+		if prov != ProvenanceMissing {
+			s.PowerW = r.power.PackageW
+			s.PowerCoreW = r.power.CoreW
+			s.PowerUncoreW = r.power.UncoreW
+			s.PowerDRAMW = r.power.DRAMW
+		}
+		s.SetSource(SignalPower, prov, backend)
+
+	case queryThrottle:
+		// 5. Kernel throttle counters. A stale delta would double-count
+		// events, so only a fresh read is used; it carries any late deltas.
+		if prov == ProvenanceStale {
+			prov = ProvenanceMissing
+		}
+		if prov != ProvenanceMissing {
+			s.CoreThrottleEvents = r.throttle.CoreEvents
+			s.PackageThrottleEvents = r.throttle.PackageEvents
+			s.CoreThrottleMs = r.throttle.CoreTimeMs
+			s.PackageThrottleMs = r.throttle.PackageTimeMs
+		}
+		s.SetSource(SignalThrottle, prov, backend)
+
+	case queryMSR:
+		// 6. Thermal status registers
+		if prov != ProvenanceMissing {
+			s.HWThrottling = r.msr.Throttling()
+			s.HWThrottleReasons = r.msr.Reasons()
+			s.TjMaxC = r.msr.TjMaxC
+			s.MSRTempC, _ = r.msr.TempC()
+		}
+		if prov == ProvenanceMeasured {
+			// A read that cleared the logs owns them; a stale copy would report them twice.
+			s.HWThrottling = s.HWThrottling || r.msr.ThrottledSinceLastRead()
+			s.HWThrottleReasons = append(s.HWThrottleReasons, r.msr.LoggedReasons()...)
+		}
+		s.SetSource(SignalThermStatus, prov, backend)
+
+	case queryQuota:
+		// 7. cgroup CPU quota. Deltas, so stale reads are dropped like the throttle counters.
+		if prov == ProvenanceStale {
+			prov = ProvenanceMissing
+		}
+		if prov != ProvenanceMissing {
+			s.CgroupPath = r.quota.Path
+			s.CgroupPeriods = r.quota.Periods
+			s.CgroupThrottledPeriods = r.quota.ThrottledPeriods
+			s.CgroupThrottledMs = float64(r.quota.ThrottledUsec) / 1000.0
+		}
+		s.SetSource(SignalQuota, prov, backend)
+
+	case queryPressure:
+		// 8. CPU pressure stall information
+		if prov != ProvenanceMissing {
+			s.PSISomeAvg10 = r.pressure.SomeAvg10
+			s.PSISomeAvg60 = r.pressure.SomeAvg60
+			s.PSISomeTotalUs = r.pressure.SomeTotalUs
+			s.PSIFullAvg10 = r.pressure.FullAvg10
+			s.PSIFullAvg60 = r.pressure.FullAvg60
+			s.PSIFullTotalUs = r.pressure.FullTotalUs
+		}
+		s.SetSource(SignalPressure, prov, backend)
+
+	case queryZones:
+		// 9. ACPI thermal zone passive cooling limits
+		if prov != ProvenanceMissing {
+			s.ZonePassiveLimit = r.zones.PassiveLimit()
+			s.ZoneThrottleReasons = r.zones.Reasons()
+		}
+		s.SetSource(SignalZones, prov, backend)
+
+	case queryFans:
+		// 10. Fans
+		if prov != ProvenanceMissing {
+			s.Fans = r.fans.Fans
+		}
+		s.SetSource(SignalFans, prov, backend)
+
+	case queryPowerSupply:
+		// 11. AC or battery
+		if prov != ProvenanceMissing {
+			s.PowerSource = r.supply.Source
+			s.HasBattery = r.supply.HasBattery
+			s.BatteryPercent = r.supply.BatteryPercent
+			s.BatteryTempC = r.supply.BatteryTempC
+		}
+		s.SetSource(SignalPowerSource, prov, backend)
+
+	case queryDeviceTemps:
+		// 12. NVMe, SoC and battery temperatures
+		if prov != ProvenanceMissing {
+			s.DeviceTempC = r.devices
+		}
+		s.SetSource(SignalDeviceTemps, prov, backend)
+	}
+}
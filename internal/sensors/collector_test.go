@@ -0,0 +1,92 @@
+package sensors
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMedianReading(t *testing.T) {
+	temps := []reading{
+		{query: queryTemp, temp: 61},
+		{query: queryTemp, temp: 99}, // spike
+		{query: queryTemp, temp: 60},
+	}
+	if got := medianReading(temps).temp; got != 61 {
+		t.Errorf("median temperature = %v, want 61", got)
+	}
+
+	freqs := []reading{
+		{query: queryFreq, freq: FrequencyData{CurrentMHz: 3400}},
+		{query: queryFreq, freq: FrequencyData{CurrentMHz: 800}}, // idle dip
+		{query: queryFreq, freq: FrequencyData{CurrentMHz: 3300}},
+		{query: queryFreq, freq: FrequencyData{CurrentMHz: 3500}},
+		{query: queryFreq, freq: FrequencyData{CurrentMHz: 3350}},
+	}
+	if got := medianReading(freqs).freq.CurrentMHz; got != 3350 {
+		t.Errorf("median frequency = %v, want 3350", got)
+	}
+}
+
+// slowThrottle is a provider whose first throttle read waits for release.
+type slowThrottle struct {
+	release chan struct{}
+	calls   int
+}
+
+func (p *slowThrottle) Name() string    { return "slow" }
+func (p *slowThrottle) Available() bool { return true }
+
+func (p *slowThrottle) Temperature(ctx context.Context) (float64, error) { return 50, nil }
+func (p *slowThrottle) Frequency(ctx context.Context) (FrequencyData, error) {
+	return FrequencyData{CurrentMHz: 3000}, nil
+}
+func (p *slowThrottle) Load(ctx context.Context) (LoadData, error) { return LoadData{Percent: 10}, nil }
+
+func (p *slowThrottle) ThrottleCounters(ctx context.Context) (ThrottleData, error) {
+	p.calls++
+	if p.calls == 1 {
+		<-p.release
+		return ThrottleData{CoreEvents: 3, CoreTimeMs: 30}, nil
+	}
+	return ThrottleData{CoreEvents: 2, CoreTimeMs: 20}, nil
+}
+
+func TestCollectorCarriesLateThrottleDeltas(t *testing.T) {
+	p := &slowThrottle{release: make(chan struct{})}
+	c := NewCollector()
+	c.Provider = p
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	s := c.Collect(ctx)
+	cancel()
+	if got := s.Sources[SignalThrottle].Provenance; got != ProvenanceMissing {
+		t.Fatalf("late throttle read: provenance = %v, want missing", got)
+	}
+
+	close(p.release)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := c.last(queryThrottle); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("late throttle read never finished")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	s = c.Collect(context.Background())
+	if got := s.Sources[SignalThrottle].Provenance; got != ProvenanceMeasured {
+		t.Fatalf("provenance = %v, want measured", got)
+	}
+	if s.CoreThrottleEvents != 5 || s.CoreThrottleMs != 50 {
+		t.Errorf("throttle = %d events / %d ms, want the late read carried over (5 / 50)",
+			s.CoreThrottleEvents, s.CoreThrottleMs)
+	}
+
+	s = c.Collect(context.Background())
+	if s.CoreThrottleEvents != 2 {
+		t.Errorf("next read: %d events, want 2 (nothing counted twice)", s.CoreThrottleEvents)
+	}
+}
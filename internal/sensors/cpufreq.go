@@ -0,0 +1,81 @@
+package sensors
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SysfsFrequency reads per-core clocks from Linux cpufreq sysfs entries.
+// Root normally is DefaultSysfsRoot, but can point at a fake tree for testing.
+type SysfsFrequency struct {
+	Root string
+}
+
+// NewSysfsFrequency returns a reader rooted at DefaultSysfsRoot.
+func NewSysfsFrequency() *SysfsFrequency {
+	return &SysfsFrequency{Root: DefaultSysfsRoot}
+}
+
+// CPUFrequency reads scaling_cur_freq for every online core.
+// BaseMHz comes from base_frequency when the driver exposes it (intel_pstate).
+// Other drivers only publish cpuinfo_max_freq, the turbo clock, which would
+// hide any drop below base; BaseMHz is left 0 for them.
+func (f *SysfsFrequency) CPUFrequency() (FrequencyData, error) {
+	dirs, err := filepath.Glob(filepath.Join(f.Root, "devices", "system", "cpu", "cpu[0-9]*", "cpufreq"))
+	if err != nil {
+		return FrequencyData{}, err
+	}
+
+	// Glob sorts lexically (cpu10 before cpu2); keep cores in numeric order.
+	sort.Slice(dirs, func(i, j int) bool {
+		return cpuIndex(dirs[i]) < cpuIndex(dirs[j])
+	})
+
+	var data FrequencyData
+	sum := 0
+	for _, dir := range dirs {
+		// cpufreq values are in kHz.
+		curKHz, err := readSysfsInt(filepath.Join(dir, "scaling_cur_freq"))
+		if err != nil {
+			continue
+		}
+		cur := int(curKHz / 1000)
+
+		if len(data.PerCoreMHz) == 0 || cur < data.MinCurrentMHz {
+			data.MinCurrentMHz = cur
+		}
+		if cur > data.MaxCurrentMHz {
+			data.MaxCurrentMHz = cur
+		}
+		data.PerCoreMHz = append(data.PerCoreMHz, cur)
+		sum += cur
+
+		if maxKHz, err := readSysfsInt(filepath.Join(dir, "cpuinfo_max_freq")); err == nil && int(maxKHz/1000) > data.MaxMHz {
+			data.MaxMHz = int(maxKHz / 1000)
+		}
+		if baseKHz, err := readSysfsInt(filepath.Join(dir, "base_frequency")); err == nil && int(baseKHz/1000) > data.BaseMHz {
+			data.BaseMHz = int(baseKHz / 1000)
+		}
+	}
+
+	if len(data.PerCoreMHz) == 0 {
+		return FrequencyData{}, fmt.Errorf("no cpufreq data found under %s", f.Root)
+	}
+
+	data.CurrentMHz = sum / len(data.PerCoreMHz)
+
+	return data, nil
+}
+
+// cpuIndex extracts N from a .../cpuN/cpufreq path.
+func cpuIndex(dir string) int {
+	name := filepath.Base(filepath.Dir(dir))
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "cpu"))
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,50 @@
+package sensors
+
+import "testing"
+
+func TestCPUFrequency(t *testing.T) {
+	f := &SysfsFrequency{Root: writeTree(t, map[string]string{
+		"devices/system/cpu/cpu0/cpufreq/scaling_cur_freq":  "2400000",
+		"devices/system/cpu/cpu0/cpufreq/cpuinfo_max_freq":  "4700000",
+		"devices/system/cpu/cpu0/cpufreq/base_frequency":    "2900000",
+		"devices/system/cpu/cpu2/cpufreq/scaling_cur_freq":  "1200000",
+		"devices/system/cpu/cpu2/cpufreq/cpuinfo_max_freq":  "4700000",
+		"devices/system/cpu/cpu10/cpufreq/scaling_cur_freq": "3000000",
+		"devices/system/cpu/cpu10/cpufreq/cpuinfo_max_freq": "4700000",
+	})}
+	got, err := f.CPUFrequency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CurrentMHz != 2200 || got.MinCurrentMHz != 1200 || got.MaxCurrentMHz != 3000 {
+		t.Errorf("current %d MHz (min %d, max %d), want 2200 (1200, 3000)", got.CurrentMHz, got.MinCurrentMHz, got.MaxCurrentMHz)
+	}
+	if got.BaseMHz != 2900 || got.MaxMHz != 4700 {
+		t.Errorf("base %d MHz, max %d MHz, want 2900 and 4700", got.BaseMHz, got.MaxMHz)
+	}
+	// Numeric order: cpu0, cpu2, cpu10.
+	want := []int{2400, 1200, 3000}
+	for i, mhz := range want {
+		if i >= len(got.PerCoreMHz) || got.PerCoreMHz[i] != mhz {
+			t.Fatalf("PerCoreMHz = %v, want %v", got.PerCoreMHz, want)
+		}
+	}
+}
+
+func TestCPUFrequencyWithoutBase(t *testing.T) {
+	// acpi-cpufreq publishes no base_frequency; the turbo clock must not stand in for it.
+	f := &SysfsFrequency{Root: writeTree(t, map[string]string{
+		"devices/system/cpu/cpu0/cpufreq/scaling_cur_freq": "3600000",
+		"devices/system/cpu/cpu0/cpufreq/cpuinfo_max_freq": "4900000",
+	})}
+	got, err := f.CPUFrequency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BaseMHz != 0 {
+		t.Errorf("BaseMHz = %d, want 0 (unknown)", got.BaseMHz)
+	}
+	if got.MaxMHz != 4900 {
+		t.Errorf("MaxMHz = %d, want 4900", got.MaxMHz)
+	}
+}
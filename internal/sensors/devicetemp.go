@@ -0,0 +1,113 @@
+package sensors
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+)
+
+// Device zones reported in Snapshot.DeviceTempC: parts besides the CPU that
+// throttle themselves when hot.
+const (
+	DeviceNVMe    = "nvme"    // NVMe drives; they slow their transfers down
+	DeviceSoC     = "soc"     // SoC thermal zones of ARM boards and GPU-less SoCs
+	DeviceBattery = "battery" // Battery packs; charging and discharge power are limited
+)
+
+// DeviceTempData holds the temperature of every device zone found, keyed by
+// zone. Each is the hottest sensor of the zone.
+type DeviceTempData map[string]float64
+
+// add records temp for zone, keeping the hottest.
+func (d DeviceTempData) add(zone string, temp float64) {
+	if old, ok := d[zone]; !ok || temp > old {
+		d[zone] = temp
+	}
+}
+
+// DeviceTemps classifies the hwmon inputs and thermal zones into device zones,
+// and adds the battery temperature from the power supply class.
+func (p *LinuxProvider) DeviceTemps(ctx context.Context) (DeviceTempData, error) {
+	hwmon, err := p.Temp.HwmonSensors()
+	if err != nil {
+		return nil, err
+	}
+	zones, err := p.Temp.ThermalZones()
+	if err != nil {
+		return nil, err
+	}
+
+	data := DeviceTempData{}
+	// An NVMe drive throttles on its Composite temperature; the other inputs
+	// are individual sensors of the controller and flash.
+	composite := map[string]bool{}
+	for _, s := range hwmon {
+		if s.Chip == "nvme" && s.Label == "Composite" {
+			composite[filepath.Dir(s.Path)] = true
+		}
+	}
+	for _, s := range hwmon {
+		if s.Chip != "nvme" {
+			continue
+		}
+		if composite[filepath.Dir(s.Path)] && s.Label != "Composite" {
+			continue
+		}
+		data.add(DeviceNVMe, s.TempC)
+	}
+	for _, z := range zones {
+		if strings.Contains(strings.ToLower(z.Chip), "soc") {
+			data.add(DeviceSoC, z.TempC)
+		}
+	}
+	if supply, err := p.Supply.Read(); err == nil && supply.HasBattery && supply.BatteryTempC != 0 {
+		data.add(DeviceBattery, supply.BatteryTempC)
+	}
+
+	if len(data) == 0 {
+		return nil, ErrUnsupported
+	}
+	return data, nil
+}
+
+// DeviceTemps picks the NVMe drive and battery temperatures out of the
+// hardware monitor's sensors.
+func (p *HardwareMonitorProvider) DeviceTemps(ctx context.Context) (DeviceTempData, error) {
+	sensors, err := p.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return deviceTempsFromSensors(sensors)
+}
+
+// deviceTempsFromSensors classifies temperature sensors by their hardware:
+// "/nvme/0", "/battery/...". Drives use their Composite temperature when they
+// publish one.
+func deviceTempsFromSensors(sensors []hmSensor) (DeviceTempData, error) {
+	data := DeviceTempData{}
+	composite := map[string]bool{}
+	for _, s := range sensors {
+		if s.Value.Valid && strings.EqualFold(s.SensorType, "Temperature") && strings.EqualFold(s.Name, "Composite Temperature") {
+			composite[s.Parent] = true
+		}
+	}
+	for _, s := range sensors {
+		if !s.Value.Valid || !strings.EqualFold(s.SensorType, "Temperature") {
+			continue
+		}
+		parent := strings.ToLower(s.Parent)
+		switch {
+		case strings.HasPrefix(parent, "/nvme/"):
+			if composite[s.Parent] && !strings.EqualFold(s.Name, "Composite Temperature") {
+				continue
+			}
+			data.add(DeviceNVMe, s.Value.Value)
+		case strings.HasPrefix(parent, "/battery/"):
+			data.add(DeviceBattery, s.Value.Value)
+		}
+	}
+	if len(data) == 0 {
+		return nil, ErrUnsupported
+	}
+	return data, nil
+}
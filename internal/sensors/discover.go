@@ -0,0 +1,435 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SensorInfo describes one sensor a backend found on the machine.
+type SensorInfo struct {
+	Backend  string  `json:"backend"`
+	Kind     string  `json:"kind"`   // temperature, frequency, load, power, energy, fan, fan-control, ...
+	Source   string  `json:"source"` // Where it comes from: hwmon, cpufreq, a WMI class, ...
+	Name     string  `json:"name"`
+	Path     string  `json:"path,omitempty"` // File or counter path, when there is one
+	Value    float64 `json:"value"`
+	Unit     string  `json:"unit,omitempty"`
+	Readable bool    `json:"readable"` // Value was read just now
+	// Unprivileged tells whether a normal user can read the sensor, without
+	// root or Administrator rights.
+	Unprivileged bool   `json:"unprivileged"`
+	Error        string `json:"error,omitempty"`
+	// Selector names a temperature sensor for a TempMapping.
+	Selector string `json:"selector,omitempty"`
+}
+
+// Discoverer is implemented by backends that can enumerate their sensors.
+type Discoverer interface {
+	Discover(ctx context.Context) ([]SensorInfo, error)
+}
+
+// BackendSensors is what one backend found.
+type BackendSensors struct {
+	Backend string       `json:"backend"`
+	Sensors []SensorInfo `json:"sensors"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// DiscoverSensors enumerates the sensors of the forced backend, or of every
+// available backend when auto-selecting. Each backend gets DefaultQueryTimeout.
+func DiscoverSensors(ctx context.Context) []BackendSensors {
+	var results []BackendSensors
+	for _, p := range candidateProviders() {
+		res := BackendSensors{Backend: p.Name(), Sensors: []SensorInfo{}}
+		d, ok := p.(Discoverer)
+		if !ok {
+			res.Error = "backend cannot list its sensors"
+			results = append(results, res)
+			continue
+		}
+		dctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+		list, err := d.Discover(dctx)
+		cancel()
+		if err != nil {
+			res.Error = err.Error()
+		}
+		for _, info := range list {
+			info.Backend = p.Name()
+			res.Sensors = append(res.Sensors, info)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// readFile fills info from an integer sysfs attribute, multiplied by scale.
+func readFile(info SensorInfo, path string, scale float64) SensorInfo {
+	info.Path = path
+	info.Unprivileged = worldReadable(path)
+	v, err := readSysfsInt(path)
+	if err != nil {
+		info.Error = errorText(err)
+		return info
+	}
+	info.Value = float64(v) * scale
+	info.Readable = true
+	return info
+}
+
+// worldReadable reports whether anyone may read path, judging by its permission
+// bits. Running as root reads everything, so trying the read would not tell.
+func worldReadable(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().Perm()&0o004 != 0
+}
+
+// errorText shortens the usual read failures.
+func errorText(err error) string {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return "permission denied"
+	case errors.Is(err, ErrAccessDenied):
+		return "access denied"
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, ErrNotFound):
+		return "not found"
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// Discover lists the hwmon chips, thermal zones, cpufreq policies, RAPL
+// domains, MSR devices, CPU pressure and power supplies.
+func (p *LinuxProvider) Discover(ctx context.Context) ([]SensorInfo, error) {
+	var list []SensorInfo
+	list = append(list, discoverHwmon(p.Temp.Root)...)
+	list = append(list, discoverThermalZones(p.Temp.Root)...)
+	list = append(list, discoverCPUFreq(p.Freq.Root)...)
+	list = append(list, discoverRAPL(p.RAPL.Root)...)
+	list = append(list, discoverMSR(p.MSR.DevRoot)...)
+	list = append(list, discoverPSI(p.PSI.ProcRoot)...)
+	list = append(list, discoverPowerSupply(p.Supply.Root)...)
+	return list, nil
+}
+
+// hwmonAttrs are the hwmon inputs listed, with their kind, unit and scale.
+var hwmonAttrs = []struct {
+	glob, kind, unit string
+	scale            float64
+}{
+	{"temp*_input", "temperature", "°C", 0.001},
+	{"fan*_input", "fan", "RPM", 1},
+	{"pwm[0-9]*", "fan-control", "%", 100.0 / 255},
+	{"power*_input", "power", "W", 0.000001},
+	{"power*_average", "power", "W", 0.000001},
+}
+
+func discoverHwmon(root string) []SensorInfo {
+	dirs, _ := filepath.Glob(filepath.Join(root, "class", "hwmon", "hwmon*"))
+	sort.Strings(dirs)
+	var list []SensorInfo
+	for _, dir := range dirs {
+		// Older drivers keep their attributes in the device/ subdirectory.
+		if _, err := os.Stat(filepath.Join(dir, "name")); err != nil {
+			dir = filepath.Join(dir, "device")
+		}
+		chip := readSysfsString(filepath.Join(dir, "name"))
+		if chip == "" {
+			continue
+		}
+		for _, attr := range hwmonAttrs {
+			files, _ := filepath.Glob(filepath.Join(dir, attr.glob))
+			sort.Strings(files)
+			for _, file := range files {
+				base := filepath.Base(file)
+				if strings.HasPrefix(base, "pwm") && strings.Contains(base, "_") {
+					continue // pwmN_enable, pwmN_mode, ...
+				}
+				name := base[:strings.IndexAny(base+"_", "_")]
+				label := readSysfsString(filepath.Join(dir, name+"_label"))
+				if label == "" {
+					label = name
+				}
+				info := readFile(SensorInfo{
+					Kind: attr.kind, Source: "hwmon", Name: chip + "/" + label, Unit: attr.unit,
+				}, file, attr.scale)
+				if attr.kind == "temperature" {
+					info.Selector = TempSensor{Source: "hwmon", Chip: chip, Label: label}.Selector()
+				}
+				list = append(list, info)
+			}
+		}
+	}
+	return list
+}
+
+func discoverThermalZones(root string) []SensorInfo {
+	dirs, _ := filepath.Glob(filepath.Join(root, "class", "thermal", "thermal_zone*"))
+	sort.Strings(dirs)
+	var list []SensorInfo
+	for _, dir := range dirs {
+		zone := TempSensor{Source: "thermal_zone", Chip: readSysfsString(filepath.Join(dir, "type")), Label: filepath.Base(dir)}
+		name := zone.Label
+		if zone.Chip != "" {
+			name += "/" + zone.Chip
+		}
+		info := readFile(SensorInfo{
+			Kind: "temperature", Source: "thermal_zone", Name: name, Unit: "°C",
+		}, filepath.Join(dir, "temp"), 0.001)
+		info.Selector = zone.Selector()
+		list = append(list, info)
+	}
+	return list
+}
+
+func discoverCPUFreq(root string) []SensorInfo {
+	dirs, _ := filepath.Glob(filepath.Join(root, "devices", "system", "cpu", "cpufreq", "policy*"))
+	sort.Slice(dirs, func(i, j int) bool { return policyIndex(dirs[i]) < policyIndex(dirs[j]) })
+	var list []SensorInfo
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		if cpus := readSysfsString(filepath.Join(dir, "related_cpus")); cpus != "" {
+			name += " (cpu " + cpus + ")"
+		}
+		if driver := readSysfsString(filepath.Join(dir, "scaling_driver")); driver != "" {
+			name += " " + driver
+		}
+		list = append(list, readFile(SensorInfo{
+			Kind: "frequency", Source: "cpufreq", Name: name, Unit: "MHz",
+		}, filepath.Join(dir, "scaling_cur_freq"), 0.001))
+	}
+	return list
+}
+
+// policyIndex extracts N from a .../policyN path.
+func policyIndex(dir string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "policy"))
+	return n
+}
+
+// discoverRAPL lists the energy counters. energy_uj is cumulative; since 2020
+// kernels only let root read it.
+func discoverRAPL(root string) []SensorInfo {
+	dirs, _ := filepath.Glob(filepath.Join(root, "class", "powercap", "intel-rapl:*"))
+	sort.Strings(dirs)
+	var list []SensorInfo
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		if domain := readSysfsString(filepath.Join(dir, "name")); domain != "" {
+			name += "/" + domain
+		}
+		list = append(list, readFile(SensorInfo{
+			Kind: "energy", Source: "rapl", Name: name, Unit: "J",
+		}, filepath.Join(dir, "energy_uj"), 0.000001))
+	}
+	return list
+}
+
+// discoverMSR lists the msr device of the first CPU; the others are the same.
+// Reading it needs root and CAP_SYS_RAWIO; only opening it is tried here, the
+// decoded register is part of status.
+func discoverMSR(devRoot string) []SensorInfo {
+	files, _ := filepath.Glob(filepath.Join(devRoot, "cpu", "[0-9]*", "msr"))
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+	info := SensorInfo{Kind: "thermal-status", Source: "msr", Name: "IA32_THERM_STATUS", Path: files[0],
+		Unprivileged: worldReadable(files[0])}
+	f, err := os.Open(files[0])
+	if err != nil {
+		info.Error = errorText(err)
+	} else {
+		f.Close()
+	}
+	return []SensorInfo{info}
+}
+
+func discoverPSI(procRoot string) []SensorInfo {
+	path := filepath.Join(procRoot, "pressure", "cpu")
+	info := SensorInfo{Kind: "pressure", Source: "psi", Name: "cpu some avg10", Path: path, Unit: "%",
+		Unprivileged: worldReadable(path)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		info.Error = errorText(err)
+		return []SensorInfo{info}
+	}
+	p, err := parsePressure(string(data))
+	if err != nil {
+		info.Error = err.Error()
+		return []SensorInfo{info}
+	}
+	info.Value = p.SomeAvg10
+	info.Readable = true
+	return []SensorInfo{info}
+}
+
+func discoverPowerSupply(root string) []SensorInfo {
+	dirs, _ := filepath.Glob(filepath.Join(root, "class", "power_supply", "*"))
+	sort.Strings(dirs)
+	var list []SensorInfo
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		switch readSysfsString(filepath.Join(dir, "type")) {
+		case "Mains", "USB":
+			list = append(list, readFile(SensorInfo{
+				Kind: "ac-online", Source: "power_supply", Name: name,
+			}, filepath.Join(dir, "online"), 1))
+		case "Battery":
+			list = append(list, readFile(SensorInfo{
+				Kind: "battery", Source: "power_supply", Name: name, Unit: "%",
+			}, filepath.Join(dir, "capacity"), 1))
+			if _, err := os.Stat(filepath.Join(dir, "temp")); err == nil {
+				list = append(list, readFile(SensorInfo{
+					Kind: "temperature", Source: "power_supply", Name: name, Unit: "°C",
+				}, filepath.Join(dir, "temp"), 0.1))
+			}
+		}
+	}
+	return list
+}
+
+// Discover lists each processor's clock and load from Win32_Processor, and the
+// ACPI thermal zones, which only Administrators can read.
+func (p *WMIProvider) Discover(ctx context.Context) ([]SensorInfo, error) {
+	procs, err := p.processors.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []SensorInfo
+	for i, proc := range procs {
+		name := fmt.Sprintf("CPU%d", i)
+		list = append(list,
+			cimSensor("frequency", "Win32_Processor", name+" CurrentClockSpeed", "MHz", proc.CurrentClockSpeed),
+			cimSensor("frequency", "Win32_Processor", name+" MaxClockSpeed", "MHz", proc.MaxClockSpeed),
+			cimSensor("load", "Win32_Processor", name+" LoadPercentage", "%", proc.LoadPercentage))
+	}
+
+	zones, err := p.TempSensors(ctx)
+	if err != nil {
+		return append(list, SensorInfo{Kind: "temperature", Source: "MSAcpi_ThermalZoneTemperature",
+			Name: "thermal zones", Unit: "°C", Error: errorText(err)}), nil
+	}
+	for _, z := range zones {
+		_, name, _ := strings.Cut(z.Selector, "/")
+		list = append(list, SensorInfo{Kind: "temperature", Source: "MSAcpi_ThermalZoneTemperature", Name: name,
+			Value: z.TempC, Unit: "°C", Readable: true, Selector: z.Selector})
+	}
+	return list, nil
+}
+
+// cimSensor describes a CIM property readable by any user.
+func cimSensor(kind, class, name, unit string, v cimNumber) SensorInfo {
+	info := SensorInfo{Kind: kind, Source: class, Name: name, Unit: unit, Unprivileged: true}
+	if v.Valid {
+		info.Value, info.Readable = v.Value, true
+	} else {
+		info.Error = "no value"
+	}
+	return info
+}
+
+// Discover lists the machine and socket totals of the processor counters and
+// every thermal zone counter. Performance counters are readable by any user.
+func (p *PerfCounterProvider) Discover(ctx context.Context) ([]SensorInfo, error) {
+	samples, err := p.counters.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []SensorInfo
+	for _, s := range samples {
+		switch s.Set() {
+		case setProcessor:
+			if _, cpu, ok := parseProcessorInstance(strings.ToLower(s.InstanceName)); !ok || cpu >= 0 {
+				continue // Logical processors would bury the rest
+			}
+			kind, unit := "performance", "%"
+			switch s.Counter() {
+			case counterUtility:
+				kind = "load"
+			case counterFrequency:
+				kind, unit = "frequency", "MHz"
+			}
+			list = append(list, counterSensor(kind, "Processor Information", unit, s, 0))
+		case setThermalZone:
+			// Many desktops have no thermal zone at all; then there are no samples.
+			switch s.Counter() {
+			case counterTemperature:
+				info := counterSensor("temperature", "Thermal Zone Information", "°C", s, -273.15)
+				info.Selector = "perfcounter:zone/" + strings.TrimSpace(s.InstanceName)
+				list = append(list, info)
+			case counterPassiveLimit:
+				list = append(list, counterSensor("passive-limit", "Thermal Zone Information", "%", s, 0))
+			case counterThrottle:
+				list = append(list, counterSensor("throttle-reasons", "Thermal Zone Information", "", s, 0))
+			}
+		}
+	}
+	return list, nil
+}
+
+// counterSensor describes one counter sample, its value shifted by offset.
+func counterSensor(kind, set, unit string, s counterSample, offset float64) SensorInfo {
+	info := cimSensor(kind, set, strings.TrimSpace(s.InstanceName)+" "+s.Counter(), unit, s.CookedValue)
+	info.Path = s.Path
+	if info.Readable {
+		info.Value += offset
+	}
+	return info
+}
+
+// hmUnits are the units of the hardware monitor sensor types.
+var hmUnits = map[string]string{
+	"temperature": "°C",
+	"clock":       "MHz",
+	"load":        "%",
+	"control":     "%",
+	"level":       "%",
+	"power":       "W",
+	"fan":         "RPM",
+	"voltage":     "V",
+	"current":     "A",
+	"energy":      "mWh",
+	"data":        "GB",
+	"smalldata":   "MB",
+	"throughput":  "B/s",
+	"flow":        "L/h",
+	"frequency":   "Hz",
+}
+
+// Discover lists every sensor the hardware monitor publishes, not just the
+// CPU's. The monitor itself runs elevated, but its WMI namespace is readable
+// by any user.
+func (p *HardwareMonitorProvider) Discover(ctx context.Context) ([]SensorInfo, error) {
+	sensors, err := p.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	group := strings.TrimPrefix(p.namespace, "root/")
+	p.mu.Unlock()
+
+	list := make([]SensorInfo, 0, len(sensors))
+	for _, s := range sensors {
+		sensorType := strings.ToLower(s.SensorType)
+		info := cimSensor(sensorType, s.Parent, s.Name, hmUnits[sensorType], s.Value)
+		info.Path = s.Identifier
+		if sensorType == "temperature" {
+			info.Selector = hmSelector(group, s)
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
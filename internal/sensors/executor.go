@@ -0,0 +1,134 @@
+package sensors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Executor runs a backend command (a PowerShell snippet for the WMI backend)
+// and returns its trimmed stdout. Failures are reported as *ExecError.
+type Executor interface {
+	Run(ctx context.Context, command string) (string, error)
+}
+
+// Error kinds an Executor can report. Match them with errors.Is.
+var (
+	ErrNotFound     = errors.New("command, class or namespace not found")
+	ErrAccessDenied = errors.New("access denied")
+	ErrTimeout      = errors.New("command timed out")
+	ErrParse        = errors.New("unparseable command output")
+)
+
+// ExecError describes a failed command. Kind is one of the Err* values above,
+// or nil for failures that fit none of them.
+type ExecError struct {
+	Kind    error
+	Command string
+	Stderr  string
+	Err     error
+}
+
+func (e *ExecError) Error() string {
+	var sb strings.Builder
+	if e.Kind != nil {
+		sb.WriteString(e.Kind.Error())
+	} else {
+		sb.WriteString("command failed")
+	}
+	if e.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+	if e.Stderr != "" {
+		sb.WriteString(": ")
+		sb.WriteString(firstLine(e.Stderr))
+	}
+	return sb.String()
+}
+
+// Unwrap exposes both the kind and the underlying error to errors.Is/As.
+func (e *ExecError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// parseError wraps an output parsing failure.
+func parseError(command string, err error) error {
+	return &ExecError{Kind: ErrParse, Command: command, Err: err}
+}
+
+// classifyStderr maps PowerShell/CIM error text onto an error kind.
+// The HRESULTs are the WBEM codes CIM cmdlets include in their messages.
+func classifyStderr(stderr string) error {
+	msg := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(msg, "access denied"),
+		strings.Contains(msg, "access is denied"),
+		strings.Contains(msg, "0x80041003"), // WBEM_E_ACCESS_DENIED
+		strings.Contains(msg, "0x80070005"): // E_ACCESSDENIED
+		return ErrAccessDenied
+	case strings.Contains(msg, "invalid class"),
+		strings.Contains(msg, "invalid namespace"),
+		strings.Contains(msg, "not found"),
+		strings.Contains(msg, "not supported"),
+		strings.Contains(msg, "is not recognized as"),
+		strings.Contains(msg, "0x80041010"), // WBEM_E_INVALID_CLASS
+		strings.Contains(msg, "0x8004100e"), // WBEM_E_INVALID_NAMESPACE
+		strings.Contains(msg, "0x8004100c"): // WBEM_E_NOT_SUPPORTED
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CommandExecutor starts one process per command. Args is the interpreter
+// command line; the command is appended as its last argument.
+type CommandExecutor struct {
+	Args []string
+}
+
+// NewPowerShellExecutor returns a one-shot executor running powershell -Command.
+func NewPowerShellExecutor() *CommandExecutor {
+	return &CommandExecutor{Args: []string{"powershell", "-NoProfile", "-NonInteractive", "-Command"}}
+}
+
+// Run starts the process under ctx, so cancellation kills it without a
+// second goroutine touching cmd.Process.
+func (e *CommandExecutor) Run(ctx context.Context, command string) (string, error) {
+	args := append(append([]string{}, e.Args[1:]...), command)
+	cmd := exec.CommandContext(ctx, e.Args[0], args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		execErr := &ExecError{Command: command, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+		switch {
+		case ctx.Err() != nil:
+			execErr.Kind, execErr.Err = ErrTimeout, ctx.Err()
+		case errors.Is(err, exec.ErrNotFound):
+			execErr.Kind = ErrNotFound
+		default:
+			execErr.Kind = classifyStderr(execErr.Stderr)
+		}
+		return "", execErr
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Fan is one fan: its speed and, when the controller reports it, the duty
+// cycle it is driven at.
+type Fan struct {
+	Name        string  // e.g. "nct6798/fan2" or "thinkpad/fan1"
+	RPM         float64 // 0 for a stopped fan or an unconnected header
+	DutyPercent float64 // Percent of full speed requested by the controller
+	HasDuty     bool    // The controller exposes its duty cycle (pwm)
+}
+
+// FanData holds every fan the backend can see.
+type FanData struct {
+	Fans []Fan
+}
+
+// SysfsFans reads fan*_input and pwm* from hwmon.
+// Root normally is DefaultSysfsRoot, but can point at a fake tree for testing.
+type SysfsFans struct {
+	Root string
+}
+
+// NewSysfsFans returns a reader rooted at DefaultSysfsRoot.
+func NewSysfsFans() *SysfsFans {
+	return &SysfsFans{Root: DefaultSysfsRoot}
+}
+
+// Fans lists every fan with a tachometer. pwmN drives fanN on the common
+// Super I/O and laptop drivers, so the two are paired by index. Machines
+// without fan sensors (most VMs, passively cooled boards) return ErrUnsupported.
+func (f *SysfsFans) Fans() (FanData, error) {
+	dirs, err := filepath.Glob(filepath.Join(f.Root, "class", "hwmon", "hwmon*"))
+	if err != nil {
+		return FanData{}, err
+	}
+
+	var data FanData
+	for _, dir := range dirs {
+		// Older drivers keep their attributes in the device/ subdirectory.
+		if _, err := os.Stat(filepath.Join(dir, "name")); err != nil {
+			dir = filepath.Join(dir, "device")
+		}
+		chip := readSysfsString(filepath.Join(dir, "name"))
+		if chip == "" {
+			continue
+		}
+
+		inputs, _ := filepath.Glob(filepath.Join(dir, "fan*_input"))
+		sort.Strings(inputs)
+		for _, input := range inputs {
+			rpm, err := readSysfsInt(input)
+			if err != nil {
+				continue
+			}
+
+			name := strings.TrimSuffix(filepath.Base(input), "_input")
+			label := readSysfsString(filepath.Join(dir, name+"_label"))
+			if label == "" {
+				label = name
+			}
+			fan := Fan{Name: chip + "/" + label, RPM: float64(rpm)}
+
+			// pwmN is 0-255.
+			pwm := "pwm" + strings.TrimPrefix(name, "fan")
+			if duty, err := readSysfsInt(filepath.Join(dir, pwm)); err == nil {
+				fan.DutyPercent = float64(duty) * 100 / 255
+				fan.HasDuty = true
+			}
+			data.Fans = append(data.Fans, fan)
+		}
+	}
+
+	if len(data.Fans) == 0 {
+		return FanData{}, ErrUnsupported
+	}
+	return data, nil
+}
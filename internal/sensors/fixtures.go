@@ -0,0 +1,174 @@
+package sensors
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// fixtureManifest is the index file of a fixture directory. Each entry points
+// at a file holding the command's raw stdout, so captured outputs can be read
+// (and hand-edited) as-is.
+const fixtureManifest = "manifest.json"
+
+// Fixture is one recorded command outcome.
+type Fixture struct {
+	Command string `json:"command"`
+	Stdout  string `json:"stdout,omitempty"` // File name relative to the fixture directory
+	Stderr  string `json:"stderr,omitempty"`
+	Kind    string `json:"kind,omitempty"` // not_found, access_denied, timeout, parse, or failed
+}
+
+var errorKinds = map[string]error{
+	"not_found":     ErrNotFound,
+	"access_denied": ErrAccessDenied,
+	"timeout":       ErrTimeout,
+	"parse":         ErrParse,
+}
+
+func kindName(kind error) string {
+	for name, k := range errorKinds {
+		if k == kind {
+			return name
+		}
+	}
+	return "failed"
+}
+
+func loadManifest(dir string) ([]Fixture, error) {
+	data, err := os.ReadFile(filepath.Join(dir, fixtureManifest))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", fixtureManifest, err)
+	}
+	return fixtures, nil
+}
+
+// RecordingExecutor passes commands to Next and saves every outcome into Dir,
+// producing a fixture directory a ReplayExecutor can serve later.
+type RecordingExecutor struct {
+	Next Executor
+	Dir  string
+
+	mu sync.Mutex
+}
+
+func NewRecordingExecutor(next Executor, dir string) (*RecordingExecutor, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &RecordingExecutor{Next: next, Dir: dir}, nil
+}
+
+func (r *RecordingExecutor) Run(ctx context.Context, command string) (string, error) {
+	output, runErr := r.Next.Run(ctx, command)
+
+	fixture := Fixture{Command: command}
+	if runErr != nil {
+		var execErr *ExecError
+		if errors.As(runErr, &execErr) {
+			fixture.Stderr = execErr.Stderr
+			if fixture.Stderr == "" && execErr.Err != nil {
+				fixture.Stderr = execErr.Err.Error()
+			}
+			fixture.Kind = kindName(execErr.Kind)
+		} else {
+			fixture.Stderr = runErr.Error()
+			fixture.Kind = kindName(nil)
+		}
+	} else {
+		sum := sha1.Sum([]byte(command))
+		fixture.Stdout = "cmd-" + hex.EncodeToString(sum[:6]) + ".out"
+	}
+
+	if err := r.save(fixture, output); err != nil {
+		// Recording is best effort; never fail the sensor read because of it.
+		fmt.Fprintf(os.Stderr, "Warning: failed to record fixture: %v\n", err)
+	}
+	return output, runErr
+}
+
+// save writes the stdout file and replaces any earlier entry for the same command.
+func (r *RecordingExecutor) save(fixture Fixture, output string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fixture.Stdout != "" {
+		if err := os.WriteFile(filepath.Join(r.Dir, fixture.Stdout), []byte(output+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+
+	fixtures, err := loadManifest(r.Dir)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range fixtures {
+		if fixtures[i].Command == fixture.Command {
+			fixtures[i] = fixture
+			replaced = true
+		}
+	}
+	if !replaced {
+		fixtures = append(fixtures, fixture)
+	}
+
+	data, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.Dir, fixtureManifest), append(data, '\n'), 0644)
+}
+
+// ReplayExecutor serves recorded outcomes from a fixture directory instead of
+// running anything, so backend code can be exercised on any OS.
+type ReplayExecutor struct {
+	Dir      string
+	fixtures map[string]Fixture
+}
+
+func NewReplayExecutor(dir string) (*ReplayExecutor, error) {
+	fixtures, err := loadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if fixtures == nil {
+		return nil, fmt.Errorf("no %s in %s", fixtureManifest, dir)
+	}
+
+	r := &ReplayExecutor{Dir: dir, fixtures: make(map[string]Fixture, len(fixtures))}
+	for _, f := range fixtures {
+		r.fixtures[f.Command] = f
+	}
+	return r, nil
+}
+
+func (r *ReplayExecutor) Run(ctx context.Context, command string) (string, error) {
+	f, ok := r.fixtures[command]
+	if !ok {
+		return "", &ExecError{Kind: ErrNotFound, Command: command, Err: fmt.Errorf("no fixture recorded in %s", r.Dir)}
+	}
+	if f.Kind != "" {
+		return "", &ExecError{Kind: errorKinds[f.Kind], Command: command, Stderr: f.Stderr}
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.Dir, f.Stdout))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
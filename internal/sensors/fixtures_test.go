@@ -0,0 +1,118 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useFixtures serves every PowerShell command from a testdata directory for
+// the duration of the test.
+func useFixtures(t *testing.T, dir string) {
+	t.Helper()
+	replay, err := NewReplayExecutor(filepath.Join("testdata", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := SetExecutor(replay)
+	t.Cleanup(func() { SetExecutor(prev) })
+}
+
+// collectFrom takes one snapshot from p with fixtures from dir.
+func collectFrom(t *testing.T, p Provider, dir string) *Snapshot {
+	t.Helper()
+	useFixtures(t, dir)
+	c := NewCollector()
+	c.Provider = p
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.Collect(ctx)
+}
+
+func TestCollectFromFixtures(t *testing.T) {
+	tests := []struct {
+		dir      string
+		provider Provider
+		tempC    float64 // 0: temperature missing
+		freqMHz  int
+		baseMHz  int
+		load     float64
+	}{
+		{"wmi-laptop", NewWMIProvider(), 55, 2592, 2592, 17},
+		{"wmi-dual-socket", NewWMIProvider(), 0, 2693, 2993, 64},
+		{"perfcounter-laptop", NewPerfCounterProvider(), 95.85, 1594, 2592, 94},
+		{"perfcounter-workstation", NewPerfCounterProvider(), 0, 2755, 2100, 100},
+		{"lhm-desktop", NewHardwareMonitorProvider(), 89.5, 4127, 3801, 98.7},
+		{"ohm-laptop", NewHardwareMonitorProvider(), 50, 1097, 1992, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			s := collectFrom(t, tt.provider, tt.dir)
+
+			if tt.tempC == 0 {
+				if s.HasSignal(SignalTemp) {
+					t.Errorf("TempC = %v, want missing", s.TempC)
+				}
+			} else if !s.HasSignal(SignalTemp) || math.Abs(s.TempC-tt.tempC) > 0.1 {
+				t.Errorf("TempC = %v (%s), want %v", s.TempC, s.Source(SignalTemp), tt.tempC)
+			}
+			if !s.HasSignal(SignalFreq) || s.FreqMHz != tt.freqMHz {
+				t.Errorf("FreqMHz = %d (%s), want %d", s.FreqMHz, s.Source(SignalFreq), tt.freqMHz)
+			}
+			if s.BaseFreqMHz != tt.baseMHz {
+				t.Errorf("BaseFreqMHz = %d, want %d", s.BaseFreqMHz, tt.baseMHz)
+			}
+			if !s.HasSignal(SignalLoad) || math.Abs(s.LoadPercent-tt.load) > 0.5 {
+				t.Errorf("LoadPercent = %v (%s), want %v", s.LoadPercent, s.Source(SignalLoad), tt.load)
+			}
+			if got := s.Source(SignalFreq).Backend; got != tt.provider.Name() {
+				t.Errorf("backend = %q, want %q", got, tt.provider.Name())
+			}
+		})
+	}
+}
+
+// cannedExecutor answers every command with the same outcome.
+type cannedExecutor struct {
+	output string
+	err    error
+}
+
+func (e cannedExecutor) Run(ctx context.Context, command string) (string, error) {
+	return e.output, e.err
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	ok, err := NewRecordingExecutor(cannedExecutor{output: `{"Value":1}`}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ok.Run(ctx, "Get-Thing"); err != nil {
+		t.Fatal(err)
+	}
+	denied, err := NewRecordingExecutor(cannedExecutor{err: &ExecError{Kind: ErrAccessDenied, Stderr: "Access denied"}}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = denied.Run(ctx, "Get-Secret")
+
+	replay, err := NewReplayExecutor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := replay.Run(ctx, "Get-Thing"); err != nil || out != `{"Value":1}` {
+		t.Errorf("Get-Thing = %q, %v", out, err)
+	}
+	if _, err := replay.Run(ctx, "Get-Secret"); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Get-Secret error = %v, want ErrAccessDenied", err)
+	}
+	if _, err := replay.Run(ctx, "Get-Unrecorded"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unrecorded command error = %v, want ErrNotFound", err)
+	}
+}
@@ -1,53 +1,86 @@
 package sensors
 
 import (
+	"context"
 	"fmt"
-	"strconv"
+	"strings"
 )
 
-// FrequencyData holds current and base frequency in MHz.
+// FrequencyData holds clock speeds in MHz.
+// CurrentMHz is the average across cores; MinCurrentMHz/MaxCurrentMHz are the
+// slowest and fastest core (or socket, when the backend only reports sockets).
+// PerCoreMHz is empty when the backend can only report an aggregate.
 type FrequencyData struct {
+	CurrentMHz    int
+	BaseMHz       int // Rated base clock, 0 if unknown
+	MaxMHz        int // Highest rated clock (turbo), 0 if unknown
+	MinCurrentMHz int
+	MaxCurrentMHz int
+	PerCoreMHz    []int
+	Sockets       []SocketFrequency
+}
+
+// SocketFrequency is the clock of one physical processor package. Cores counts
+// physical cores and Threads logical processors; either is 0 when the backend
+// cannot tell.
+type SocketFrequency struct {
+	Name       string
+	Cores      int
+	Threads    int
 	CurrentMHz int
 	BaseMHz    int
 }
 
-// GetCPUFrequency fetches the current and max clock speed.
-func GetCPUFrequency() (FrequencyData, error) {
-
-	// We need to parse JSON output efficiently without deep structs if possible,
-	// or just simple string manipulation if we want to avoid complex JSON parsing for a simple output.
-	// However, PowerShell JSON output can be a list or single object.
-	// Let's use a more direct specific query to avoid JSON parsing issues for multi-socket systems.
-	// We'll take the average or first processor found.
-
-	// Simplified approach: Get Max (Base) and Current separately to ensure stability.
-
-	// Get Current
-	cmdCurr := "Get-CimInstance -ClassName Win32_Processor | Select-Object -ExpandProperty CurrentClockSpeed | Measure-Object -Average | Select-Object -ExpandProperty Average"
-	outCurr, err := execPowerShell(cmdCurr)
+// Frequency fetches the current and max clock speed of every Win32_Processor.
+func (p *WMIProvider) Frequency(ctx context.Context) (FrequencyData, error) {
+	procs, err := p.processors.get(ctx)
 	if err != nil {
 		return FrequencyData{}, err
 	}
+	return frequencyFromProcessors(procs)
+}
 
-	// Get Base (Max)
-	cmdBase := "Get-CimInstance -ClassName Win32_Processor | Select-Object -ExpandProperty MaxClockSpeed | Measure-Object -Average | Select-Object -ExpandProperty Average"
-	outBase, err := execPowerShell(cmdBase)
-	if err != nil {
-		return FrequencyData{}, err
-	}
+// frequencyFromProcessors aggregates per-socket clocks. Averages are weighted by
+// core count so a small second socket does not skew the result.
+func frequencyFromProcessors(procs []win32Processor) (FrequencyData, error) {
+	var data FrequencyData
+	var currSum, baseSum, weightSum float64
 
-	currMHz, err := strconv.ParseFloat(outCurr, 64)
-	if err != nil {
-		return FrequencyData{}, fmt.Errorf("failed to parse current freq: %v", err)
+	for _, proc := range procs {
+		if !proc.CurrentClockSpeed.Valid || !proc.MaxClockSpeed.Valid {
+			continue
+		}
+		socket := SocketFrequency{
+			Name:       strings.TrimSpace(proc.Name),
+			Cores:      int(proc.NumberOfCores.Value),
+			CurrentMHz: int(proc.CurrentClockSpeed.Value),
+			BaseMHz:    int(proc.MaxClockSpeed.Value),
+		}
+
+		if len(data.Sockets) == 0 || socket.CurrentMHz < data.MinCurrentMHz {
+			data.MinCurrentMHz = socket.CurrentMHz
+		}
+		if socket.CurrentMHz > data.MaxCurrentMHz {
+			data.MaxCurrentMHz = socket.CurrentMHz
+		}
+		data.Sockets = append(data.Sockets, socket)
+
+		weight := float64(socket.Cores)
+		if weight <= 0 {
+			weight = 1
+		}
+		currSum += proc.CurrentClockSpeed.Value * weight
+		baseSum += proc.MaxClockSpeed.Value * weight
+		weightSum += weight
 	}
 
-	baseMHz, err := strconv.ParseFloat(outBase, 64)
-	if err != nil {
-		return FrequencyData{}, fmt.Errorf("failed to parse base freq: %v", err)
+	if len(data.Sockets) == 0 {
+		return FrequencyData{}, fmt.Errorf("no processor reported clock speeds")
 	}
 
-	return FrequencyData{
-		CurrentMHz: int(currMHz),
-		BaseMHz:    int(baseMHz),
-	}, nil
+	// MaxClockSpeed is the rated (base) clock; WMI has no turbo figure.
+	data.CurrentMHz = int(currSum / weightSum)
+	data.BaseMHz = int(baseSum / weightSum)
+	data.MaxMHz = data.BaseMHz
+	return data, nil
 }
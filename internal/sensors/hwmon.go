@@ -0,0 +1,235 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultSysfsRoot is where the Linux kernel exposes hwmon, thermal and cpufreq entries.
+const DefaultSysfsRoot = "/sys"
+
+// cpuHwmonChips are hwmon drivers that report the CPU package itself.
+// Anything else (nvme, acpitz, amdgpu, ...) is ignored when picking the CPU temperature.
+var cpuHwmonChips = []string{"coretemp", "k10temp", "zenpower"}
+
+// packageLabelPrefixes are the temp*_label values that represent the whole package,
+// in order of preference. coretemp uses "Package id N", k10temp/zenpower use Tctl/Tdie.
+var packageLabelPrefixes = []string{"Package id", "Tctl", "Tdie"}
+
+// TempSensor is a single temperature input found under sysfs.
+type TempSensor struct {
+	Source string // "hwmon" or "thermal_zone"
+	Chip   string // hwmon driver name (coretemp, k10temp, ...) or thermal zone type
+	Label  string // temp*_label, or the input/zone name when no label exists
+	Path   string
+	TempC  float64
+}
+
+// Selector names the sensor for a TempMapping: "hwmon:k10temp/Tctl" or
+// "zone:x86_pkg_temp/thermal_zone2".
+func (s TempSensor) Selector() string {
+	family := "hwmon"
+	if s.Source == "thermal_zone" {
+		family = "zone"
+	}
+	return family + ":" + s.Chip + "/" + s.Label
+}
+
+// SysfsTemperature reads CPU temperature from hwmon and thermal_zone entries.
+// Root normally is DefaultSysfsRoot, but can point at a fake tree for testing.
+type SysfsTemperature struct {
+	Root string
+}
+
+// NewSysfsTemperature returns a reader rooted at DefaultSysfsRoot.
+func NewSysfsTemperature() *SysfsTemperature {
+	return &SysfsTemperature{Root: DefaultSysfsRoot}
+}
+
+// CPUTemperature returns the package temperature in Celsius.
+// hwmon CPU drivers are preferred over thermal zones, since zones on many
+// machines are ACPI approximations or belong to other devices.
+func (t *SysfsTemperature) CPUTemperature() (float64, error) {
+	hwmon, err := t.HwmonSensors()
+	if err != nil {
+		return 0, err
+	}
+	if temp, ok := pickPackageTemp(hwmon); ok {
+		return temp, nil
+	}
+
+	zones, err := t.ThermalZones()
+	if err != nil {
+		return 0, err
+	}
+	if temp, ok := pickZoneTemp(zones); ok {
+		return temp, nil
+	}
+
+	return 0, fmt.Errorf("no CPU temperature sensor found under %s", t.Root)
+}
+
+// HwmonSensors lists every readable temp*_input under class/hwmon.
+func (t *SysfsTemperature) HwmonSensors() ([]TempSensor, error) {
+	dirs, err := filepath.Glob(filepath.Join(t.Root, "class", "hwmon", "hwmon*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var sensors []TempSensor
+	for _, dir := range dirs {
+		// Older drivers keep their attributes in the device/ subdirectory.
+		if _, err := os.Stat(filepath.Join(dir, "name")); err != nil {
+			dir = filepath.Join(dir, "device")
+		}
+		chip := readSysfsString(filepath.Join(dir, "name"))
+		if chip == "" {
+			continue
+		}
+
+		inputs, _ := filepath.Glob(filepath.Join(dir, "temp*_input"))
+		for _, input := range inputs {
+			milli, err := readSysfsInt(input)
+			if err != nil {
+				// Unpopulated inputs return EIO/ENODATA; skip them.
+				continue
+			}
+
+			name := strings.TrimSuffix(filepath.Base(input), "_input")
+			label := readSysfsString(filepath.Join(dir, name+"_label"))
+			if label == "" {
+				label = name
+			}
+
+			sensors = append(sensors, TempSensor{
+				Source: "hwmon",
+				Chip:   chip,
+				Label:  label,
+				Path:   input,
+				TempC:  float64(milli) / 1000.0,
+			})
+		}
+	}
+
+	return sensors, nil
+}
+
+// ThermalZones lists every readable class/thermal/thermal_zone*/temp.
+func (t *SysfsTemperature) ThermalZones() ([]TempSensor, error) {
+	dirs, err := filepath.Glob(filepath.Join(t.Root, "class", "thermal", "thermal_zone*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []TempSensor
+	for _, dir := range dirs {
+		input := filepath.Join(dir, "temp")
+		milli, err := readSysfsInt(input)
+		if err != nil {
+			continue
+		}
+		zones = append(zones, TempSensor{
+			Source: "thermal_zone",
+			Chip:   readSysfsString(filepath.Join(dir, "type")),
+			Label:  filepath.Base(dir),
+			Path:   input,
+			TempC:  float64(milli) / 1000.0,
+		})
+	}
+
+	return zones, nil
+}
+
+// pickPackageTemp chooses the package sensor from a known CPU hwmon driver.
+// If the driver has no package label (e.g. some coretemp setups), the hottest core is used.
+func pickPackageTemp(sensors []TempSensor) (float64, bool) {
+	for _, chip := range cpuHwmonChips {
+		var chipSensors []TempSensor
+		for _, s := range sensors {
+			if s.Chip == chip {
+				chipSensors = append(chipSensors, s)
+			}
+		}
+		if len(chipSensors) == 0 {
+			continue
+		}
+
+		for _, prefix := range packageLabelPrefixes {
+			// Multi-socket machines have several "Package id N"; report the hottest.
+			found := false
+			hottest := 0.0
+			for _, s := range chipSensors {
+				if strings.HasPrefix(s.Label, prefix) && (!found || s.TempC > hottest) {
+					hottest = s.TempC
+					found = true
+				}
+			}
+			if found {
+				return hottest, true
+			}
+		}
+
+		return maxTemp(chipSensors), true
+	}
+
+	return 0, false
+}
+
+// pickZoneTemp chooses the most CPU-like thermal zone.
+// x86_pkg_temp is the package sensor exposed by intel_powerclamp; otherwise any
+// zone typed as cpu/soc is used, and only then the hottest zone overall.
+func pickZoneTemp(zones []TempSensor) (float64, bool) {
+	if len(zones) == 0 {
+		return 0, false
+	}
+
+	var pkg, cpu []TempSensor
+	for _, z := range zones {
+		zoneType := strings.ToLower(z.Chip)
+		switch {
+		case zoneType == "x86_pkg_temp":
+			pkg = append(pkg, z)
+		case strings.Contains(zoneType, "cpu") || strings.Contains(zoneType, "soc"):
+			cpu = append(cpu, z)
+		}
+	}
+
+	if len(pkg) > 0 {
+		return maxTemp(pkg), true
+	}
+	if len(cpu) > 0 {
+		return maxTemp(cpu), true
+	}
+	return maxTemp(zones), true
+}
+
+func maxTemp(sensors []TempSensor) float64 {
+	hottest := sensors[0].TempC
+	for _, s := range sensors[1:] {
+		if s.TempC > hottest {
+			hottest = s.TempC
+		}
+	}
+	return hottest
+}
+
+// readSysfsString reads a single-line sysfs attribute. Missing files yield "".
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSysfsInt reads a sysfs attribute holding a single integer.
+func readSysfsInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
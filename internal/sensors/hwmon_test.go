@@ -0,0 +1,152 @@
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree creates a fake sysfs tree under a temporary root. Keys are paths
+// relative to the root, values the file contents.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCPUTemperature(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  float64
+	}{
+		{
+			name: "coretemp package preferred over cores and other chips",
+			files: map[string]string{
+				"class/hwmon/hwmon0/name":        "nvme",
+				"class/hwmon/hwmon0/temp1_input": "91000",
+				"class/hwmon/hwmon1/name":        "coretemp",
+				"class/hwmon/hwmon1/temp1_input": "64000",
+				"class/hwmon/hwmon1/temp1_label": "Package id 0",
+				"class/hwmon/hwmon1/temp2_input": "70000",
+				"class/hwmon/hwmon1/temp2_label": "Core 0",
+			},
+			want: 64,
+		},
+		{
+			name: "hottest package of a dual socket machine",
+			files: map[string]string{
+				"class/hwmon/hwmon0/name":        "coretemp",
+				"class/hwmon/hwmon0/temp1_input": "55000",
+				"class/hwmon/hwmon0/temp1_label": "Package id 0",
+				"class/hwmon/hwmon1/name":        "coretemp",
+				"class/hwmon/hwmon1/temp1_input": "61500",
+				"class/hwmon/hwmon1/temp1_label": "Package id 1",
+			},
+			want: 61.5,
+		},
+		{
+			name: "k10temp Tctl",
+			files: map[string]string{
+				"class/hwmon/hwmon2/name":        "k10temp",
+				"class/hwmon/hwmon2/temp1_input": "72250",
+				"class/hwmon/hwmon2/temp1_label": "Tctl",
+				"class/hwmon/hwmon2/temp3_input": "68000",
+				"class/hwmon/hwmon2/temp3_label": "Tccd1",
+			},
+			want: 72.25,
+		},
+		{
+			name: "attributes in the device subdirectory",
+			files: map[string]string{
+				"class/hwmon/hwmon0/device/name":        "coretemp",
+				"class/hwmon/hwmon0/device/temp1_input": "48000",
+			},
+			want: 48,
+		},
+		{
+			name: "x86_pkg_temp zone when no hwmon CPU driver",
+			files: map[string]string{
+				"class/thermal/thermal_zone0/type": "acpitz",
+				"class/thermal/thermal_zone0/temp": "80000",
+				"class/thermal/thermal_zone1/type": "x86_pkg_temp",
+				"class/thermal/thermal_zone1/temp": "57000",
+			},
+			want: 57,
+		},
+		{
+			name: "cpu typed zone",
+			files: map[string]string{
+				"class/thermal/thermal_zone0/type": "gpu-thermal",
+				"class/thermal/thermal_zone0/temp": "66000",
+				"class/thermal/thermal_zone1/type": "cpu-thermal",
+				"class/thermal/thermal_zone1/temp": "52000",
+			},
+			want: 52,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			temp := &SysfsTemperature{Root: writeTree(t, tt.files)}
+			got, err := temp.CPUTemperature()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("CPUTemperature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCPUTemperatureNoSensor(t *testing.T) {
+	temp := &SysfsTemperature{Root: writeTree(t, map[string]string{
+		"class/hwmon/hwmon0/name":        "coretemp",
+		"class/hwmon/hwmon0/temp1_input": "not a number",
+	})}
+	if _, err := temp.CPUTemperature(); err == nil {
+		t.Error("CPUTemperature() succeeded without a readable sensor")
+	}
+}
+
+func TestHwmonSelectors(t *testing.T) {
+	temp := &SysfsTemperature{Root: writeTree(t, map[string]string{
+		"class/hwmon/hwmon0/name":          "k10temp",
+		"class/hwmon/hwmon0/temp1_input":   "60000",
+		"class/hwmon/hwmon0/temp1_label":   "Tctl",
+		"class/hwmon/hwmon0/temp2_input":   "58000",
+		"class/thermal/thermal_zone3/type": "x86_pkg_temp",
+		"class/thermal/thermal_zone3/temp": "50000",
+	})}
+	hwmon, err := temp.HwmonSensors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones, err := temp.ThermalZones()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range append(hwmon, zones...) {
+		got = append(got, s.Selector())
+	}
+	want := []string{"hwmon:k10temp/Tctl", "hwmon:k10temp/temp2", "zone:x86_pkg_temp/thermal_zone3"}
+	if len(got) != len(want) {
+		t.Fatalf("selectors = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("selectors = %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,507 @@
+package sensors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hardware monitor WMI namespaces, in order of preference. LibreHardwareMonitor
+// is the maintained fork of OpenHardwareMonitor and publishes the same Sensor
+// class; both only do so while the application is running.
+var hardwareMonitorNamespaces = []string{"root/LibreHardwareMonitor", "root/OpenHardwareMonitor"}
+
+// namespaceQuery lists which of the hardware monitor namespaces exist.
+const namespaceQuery = "Get-CimInstance -Namespace root -ClassName __NAMESPACE " +
+	"-Filter \"Name='LibreHardwareMonitor' OR Name='OpenHardwareMonitor'\" | Select-Object -ExpandProperty Name"
+
+// hmSensorQuery returns the query for every Sensor object in namespace.
+func hmSensorQuery(namespace string) string {
+	return "Get-CimInstance -Namespace " + namespace + " -ClassName Sensor | " +
+		"Select-Object Identifier,Name,SensorType,Parent,Value | ConvertTo-Json -Compress"
+}
+
+// HardwareMonitorProvider reads sensors from LibreHardwareMonitor or
+// OpenHardwareMonitor over WMI. They read the CPU's own sensors through a
+// kernel driver, so unlike ACPI thermal zones the temperature is the real
+// package temperature and the clocks are per-core and current. The monitors
+// do not publish the rated clock; it comes from Win32_Processor.
+type HardwareMonitorProvider struct {
+	processors processorCache // Win32_Processor, for the base clock
+
+	mu        sync.Mutex
+	namespace string // Discovered namespace, "" until found or after it stopped answering
+	searched  time.Time
+	searchErr error
+	searching bool // A background search is running
+	fetched   time.Time
+	sensors   []hmSensor
+}
+
+// NewHardwareMonitorProvider returns the LibreHardwareMonitor/OpenHardwareMonitor backend.
+func NewHardwareMonitorProvider() *HardwareMonitorProvider {
+	return &HardwareMonitorProvider{}
+}
+
+func (p *HardwareMonitorProvider) Name() string { return "lhm" }
+
+// Available reports whether a hardware monitor is publishing its sensors.
+// Only the first search is waited for; later ones run in the background, so
+// auto-selection on the collection path never waits for PowerShell.
+func (p *HardwareMonitorProvider) Available() bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	if _, err := exec.LookPath("powershell"); err != nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case p.namespace != "":
+		return true
+	case p.searched.IsZero() && !p.searching:
+		ctx, cancel := context.WithTimeout(context.Background(), AvailableTimeout)
+		defer cancel()
+		p.recordLocked(findHMNamespace(ctx))
+		return p.namespace != ""
+	case time.Since(p.searched) >= discoveryRetry:
+		p.searchInBackgroundLocked()
+	}
+	return false
+}
+
+// discoveryRetry is how long a failed namespace search is remembered. The
+// monitor may be started after us, but auto-selection asks on every tick.
+const discoveryRetry = 30 * time.Second
+
+// discoverLocked returns the namespace to read. A forced backend is never
+// asked Available, so its first search runs here, bounded by ctx; later
+// ones run in the background.
+func (p *HardwareMonitorProvider) discoverLocked(ctx context.Context) (string, error) {
+	switch {
+	case p.namespace != "":
+		return p.namespace, nil
+	case p.searched.IsZero() && !p.searching:
+		p.recordLocked(findHMNamespace(ctx))
+		return p.namespace, p.searchErr
+	case time.Since(p.searched) >= discoveryRetry:
+		p.searchInBackgroundLocked()
+	}
+	if p.searchErr == nil {
+		return "", &ExecError{Kind: ErrNotFound, Command: namespaceQuery, Err: fmt.Errorf("still looking for a hardware monitor")}
+	}
+	return "", p.searchErr
+}
+
+// searchInBackgroundLocked starts a namespace search unless one is running.
+func (p *HardwareMonitorProvider) searchInBackgroundLocked() {
+	if p.searching {
+		return
+	}
+	p.searching = true
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), AvailableTimeout)
+		defer cancel()
+		ns, err := findHMNamespace(ctx)
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.searching = false
+		p.recordLocked(ns, err)
+	}()
+}
+
+// recordLocked keeps the outcome of a namespace search.
+func (p *HardwareMonitorProvider) recordLocked(ns string, err error) {
+	p.namespace, p.searchErr, p.searched = ns, err, time.Now()
+}
+
+// lostLocked forgets the namespace after a failed query: the monitor may have
+// exited. Until a background search finds it again, the backend reports
+// itself unavailable and auto-selection falls back to the next one.
+func (p *HardwareMonitorProvider) lostLocked(err error) {
+	p.recordLocked("", err)
+	p.sensors = nil
+	p.searchInBackgroundLocked()
+}
+
+// findHMNamespace returns the first hardware monitor namespace that exists.
+func findHMNamespace(ctx context.Context) (string, error) {
+	output, err := execPowerShell(ctx, namespaceQuery)
+	if err != nil {
+		return "", err
+	}
+	found := strings.Fields(output)
+	for _, ns := range hardwareMonitorNamespaces {
+		for _, name := range found {
+			if strings.EqualFold("root/"+name, ns) {
+				return ns, nil
+			}
+		}
+	}
+	return "", &ExecError{Kind: ErrNotFound, Command: namespaceQuery,
+		Err: fmt.Errorf("neither LibreHardwareMonitor nor OpenHardwareMonitor is running")}
+}
+
+// sensorCacheTTL lets the reads of one tick share a single Sensor query.
+const sensorCacheTTL = time.Second
+
+// get returns the cached sensors if fresh, otherwise queries them.
+func (p *HardwareMonitorProvider) get(ctx context.Context) ([]hmSensor, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sensors != nil && time.Since(p.fetched) < sensorCacheTTL {
+		return p.sensors, nil
+	}
+	ns, err := p.discoverLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := hmSensorQuery(ns)
+	output, err := execPowerShell(ctx, query)
+	if err != nil {
+		if ctx.Err() == nil { // Running out of tick time says nothing about the monitor
+			p.lostLocked(err)
+		}
+		return nil, err
+	}
+	sensors, err := parseHMSensors([]byte(output))
+	if err != nil {
+		return nil, parseError(query, err)
+	}
+
+	p.sensors = sensors
+	p.fetched = time.Now()
+	return sensors, nil
+}
+
+// Temperature returns the CPU package temperature.
+func (p *HardwareMonitorProvider) Temperature(ctx context.Context) (float64, error) {
+	sensors, err := p.get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return cpuTempFromSensors(sensors)
+}
+
+// TempSensors lists every temperature sensor the monitor publishes, CPU or not.
+func (p *HardwareMonitorProvider) TempSensors(ctx context.Context) ([]NamedTemp, error) {
+	sensors, err := p.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	group := strings.TrimPrefix(p.namespace, "root/")
+	p.mu.Unlock()
+
+	var list []NamedTemp
+	for _, s := range sensors {
+		if s.Value.Valid && strings.EqualFold(s.SensorType, "Temperature") {
+			list = append(list, NamedTemp{Selector: hmSelector(group, s), Path: s.Identifier, TempC: s.Value.Value})
+		}
+	}
+	return list, nil
+}
+
+// hmSelector names a sensor for a TempMapping by its hardware and name, e.g.
+// "wmi:LibreHardwareMonitor/intelcpu/0/CPU Package". Names repeat across
+// hardware (every socket has a "CPU Package", many drives a "Temperature"),
+// so the name alone would be ambiguous.
+func hmSelector(group string, s hmSensor) string {
+	hardware := strings.Trim(s.Parent, "/")
+	if hardware == "" {
+		return "wmi:" + group + "/" + s.Name
+	}
+	return "wmi:" + group + "/" + hardware + "/" + s.Name
+}
+
+// Frequency returns the per-core clocks, with the base clock from Win32_Processor.
+func (p *HardwareMonitorProvider) Frequency(ctx context.Context) (FrequencyData, error) {
+	sensors, err := p.get(ctx)
+	if err != nil {
+		return FrequencyData{}, err
+	}
+	data, err := frequencyFromSensors(sensors)
+	if err != nil {
+		return FrequencyData{}, err
+	}
+	if procs, err := p.processors.get(ctx); err == nil {
+		if rated, err := frequencyFromProcessors(procs); err == nil {
+			data.BaseMHz = rated.BaseMHz
+		}
+	}
+	return data, nil
+}
+
+// Load returns the "CPU Total" load and the per-core loads.
+func (p *HardwareMonitorProvider) Load(ctx context.Context) (LoadData, error) {
+	sensors, err := p.get(ctx)
+	if err != nil {
+		return LoadData{}, err
+	}
+	return loadFromSensors(sensors)
+}
+
+// Power returns the package power, summed over sockets.
+func (p *HardwareMonitorProvider) Power(ctx context.Context) (PowerData, error) {
+	sensors, err := p.get(ctx)
+	if err != nil {
+		return PowerData{}, err
+	}
+	return powerFromSensors(sensors)
+}
+
+// Fans returns every fan tachometer, with the duty cycle of the fan control of
+// the same name.
+func (p *HardwareMonitorProvider) Fans(ctx context.Context) (FanData, error) {
+	sensors, err := p.get(ctx)
+	if err != nil {
+		return FanData{}, err
+	}
+	return fansFromSensors(sensors)
+}
+
+// hmSensor mirrors the fields selected from one Sensor object. Identifier is
+// a path such as "/intelcpu/0/temperature/6"; Parent is the hardware's path.
+type hmSensor struct {
+	Identifier string
+	Name       string
+	SensorType string
+	Parent     string
+	Value      cimNumber
+}
+
+// cpuHardware matches the Parent of CPU sensors: "/intelcpu/0", "/amdcpu/1", ...
+var cpuHardware = regexp.MustCompile(`^/[a-z]*cpu/\d+$`)
+
+// coreName matches per-core sensor names: "CPU Core #3" in OpenHardwareMonitor
+// and older LibreHardwareMonitor versions, "Core #3" in newer ones.
+var coreName = regexp.MustCompile(`^(?:CPU )?Core #(\d+)$`)
+
+// isCPU reports whether s is a sensor of a CPU, of the given type, with a value.
+func (s hmSensor) isCPU(sensorType string) bool {
+	return s.Value.Valid && strings.EqualFold(s.SensorType, sensorType) && cpuHardware.MatchString(strings.ToLower(s.Parent))
+}
+
+// coreIndex returns N for a "Core #N" sensor.
+func (s hmSensor) coreIndex() (int, bool) {
+	m := coreName.FindStringSubmatch(s.Name)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	return n, err == nil
+}
+
+// parseHMSensors decodes the Sensor query output, accepting both a single
+// object and an array.
+func parseHMSensors(output []byte) ([]hmSensor, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, fmt.Errorf("no sensors returned")
+	}
+
+	var sensors []hmSensor
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &sensors); err != nil {
+			return nil, fmt.Errorf("failed to parse sensors: %v", err)
+		}
+	} else {
+		var s hmSensor
+		if err := json.Unmarshal(output, &s); err != nil {
+			return nil, fmt.Errorf("failed to parse sensors: %v", err)
+		}
+		sensors = append(sensors, s)
+	}
+
+	if len(sensors) == 0 {
+		return nil, fmt.Errorf("no sensors returned")
+	}
+	return sensors, nil
+}
+
+// tempRank orders CPU temperature sensors by how well they represent the
+// package: lower is better, -1 means the sensor is not a CPU-wide reading.
+func tempRank(name string) int {
+	switch {
+	case name == "CPU Package" || name == "Package":
+		return 0
+	case strings.Contains(name, "Tctl") || strings.Contains(name, "Tdie"):
+		// AMD: "Core (Tctl/Tdie)", older "CPU (Tctl/Tdie)"
+		return 1
+	case name == "Core Max":
+		return 2
+	case coreName.MatchString(name):
+		return 3
+	}
+	return -1
+}
+
+// cpuTempFromSensors returns the best-ranked CPU temperature; with several
+// sockets or cores of the same rank, the hottest.
+func cpuTempFromSensors(sensors []hmSensor) (float64, error) {
+	best, temp := -1, 0.0
+	for _, s := range sensors {
+		if !s.isCPU("Temperature") {
+			continue
+		}
+		rank := tempRank(s.Name)
+		switch {
+		case rank < 0:
+		case best < 0 || rank < best:
+			best, temp = rank, s.Value.Value
+		case rank == best && s.Value.Value > temp:
+			temp = s.Value.Value
+		}
+	}
+	if best < 0 {
+		return 0, &ExecError{Kind: ErrNotFound, Err: fmt.Errorf("no CPU temperature sensor")}
+	}
+	return temp, nil
+}
+
+// frequencyFromSensors aggregates the per-core clocks of every CPU. Bus speed
+// and other clocks are ignored.
+func frequencyFromSensors(sensors []hmSensor) (FrequencyData, error) {
+	type core struct {
+		parent string
+		index  int
+		mhz    float64
+	}
+	var cores []core
+	for _, s := range sensors {
+		if !s.isCPU("Clock") {
+			continue
+		}
+		if n, ok := s.coreIndex(); ok {
+			cores = append(cores, core{strings.ToLower(s.Parent), n, s.Value.Value})
+		}
+	}
+	if len(cores) == 0 {
+		return FrequencyData{}, &ExecError{Kind: ErrNotFound, Err: fmt.Errorf("no CPU core clock sensors")}
+	}
+	sort.Slice(cores, func(i, j int) bool {
+		if cores[i].parent != cores[j].parent {
+			return cores[i].parent < cores[j].parent
+		}
+		return cores[i].index < cores[j].index
+	})
+
+	var data FrequencyData
+	var sum float64
+	for i, c := range cores {
+		mhz := int(c.mhz + 0.5)
+		if i == 0 || mhz < data.MinCurrentMHz {
+			data.MinCurrentMHz = mhz
+		}
+		if mhz > data.MaxCurrentMHz {
+			data.MaxCurrentMHz = mhz
+		}
+		data.PerCoreMHz = append(data.PerCoreMHz, mhz)
+		sum += c.mhz
+
+		if n := len(data.Sockets); n == 0 || data.Sockets[n-1].Name != c.parent {
+			data.Sockets = append(data.Sockets, SocketFrequency{Name: c.parent})
+		}
+		socket := &data.Sockets[len(data.Sockets)-1]
+		socket.CurrentMHz += mhz // Summed here, averaged below
+		socket.Cores++
+	}
+	for i := range data.Sockets {
+		data.Sockets[i].CurrentMHz /= data.Sockets[i].Cores
+	}
+	data.CurrentMHz = int(sum/float64(len(cores)) + 0.5)
+	return data, nil
+}
+
+// loadFromSensors reads "CPU Total" and the per-core loads. With several
+// sockets the totals are averaged.
+func loadFromSensors(sensors []hmSensor) (LoadData, error) {
+	var data LoadData
+	for _, s := range sensors {
+		if !s.isCPU("Load") {
+			continue
+		}
+		if s.Name == "CPU Total" {
+			data.PerSocket = append(data.PerSocket, s.Value.Value)
+		} else if _, ok := s.coreIndex(); ok {
+			data.PerCPU = append(data.PerCPU, s.Value.Value)
+		}
+	}
+	if len(data.PerSocket) == 0 {
+		return LoadData{}, &ExecError{Kind: ErrNotFound, Err: fmt.Errorf("no CPU Total load sensor")}
+	}
+	for _, v := range data.PerSocket {
+		data.Percent += v
+	}
+	data.Percent /= float64(len(data.PerSocket))
+	return data, nil
+}
+
+// powerFromSensors sums the CPU power sensors over sockets.
+func powerFromSensors(sensors []hmSensor) (PowerData, error) {
+	var data PowerData
+	found := false
+	for _, s := range sensors {
+		if !s.isCPU("Power") {
+			continue
+		}
+		switch s.Name {
+		case "CPU Package", "Package":
+			data.PackageW += s.Value.Value
+			found = true
+		case "CPU Cores":
+			data.CoreW += s.Value.Value
+		case "CPU Graphics":
+			data.UncoreW += s.Value.Value
+		case "CPU Memory", "CPU DRAM":
+			data.DRAMW += s.Value.Value
+		}
+	}
+	if !found {
+		return PowerData{}, ErrUnsupported
+	}
+	return data, nil
+}
+
+// fansFromSensors pairs Fan (RPM) sensors with the Control (percent) sensor
+// of the same name on the same chip, e.g. "Fan #2" on "/lpc/nct6798d".
+func fansFromSensors(sensors []hmSensor) (FanData, error) {
+	duty := map[string]float64{}
+	for _, s := range sensors {
+		if s.Value.Valid && strings.EqualFold(s.SensorType, "Control") {
+			duty[s.Parent+"\x00"+s.Name] = s.Value.Value
+		}
+	}
+
+	var data FanData
+	for _, s := range sensors {
+		if !s.Value.Valid || !strings.EqualFold(s.SensorType, "Fan") {
+			continue
+		}
+		chip := s.Parent
+		if i := strings.LastIndex(chip, "/"); i >= 0 {
+			chip = chip[i+1:]
+		}
+		fan := Fan{Name: chip + "/" + s.Name, RPM: s.Value.Value}
+		if d, ok := duty[s.Parent+"\x00"+s.Name]; ok {
+			fan.DutyPercent = d
+			fan.HasDuty = true
+		}
+		data.Fans = append(data.Fans, fan)
+	}
+	if len(data.Fans) == 0 {
+		return FanData{}, ErrUnsupported
+	}
+	return data, nil
+}
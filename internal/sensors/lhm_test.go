@@ -0,0 +1,236 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// hmFixture decodes the Sensor query output captured in a testdata directory.
+func hmFixture(t *testing.T, dir, name string) []hmSensor {
+	t.Helper()
+	sensors, err := parseHMSensors(readFixture(t, dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sensors
+}
+
+func TestParseHMSensors(t *testing.T) {
+	single, err := parseHMSensors([]byte(`{"Identifier":"/intelcpu/0/temperature/0","Name":"CPU Package","SensorType":"Temperature","Parent":"/intelcpu/0","Value":61.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []hmSensor{{
+		Identifier: "/intelcpu/0/temperature/0",
+		Name:       "CPU Package",
+		SensorType: "Temperature",
+		Parent:     "/intelcpu/0",
+		Value:      cimNumber{Value: 61.5, Valid: true},
+	}}
+	if !reflect.DeepEqual(single, want) {
+		t.Errorf("single object = %+v, want %+v", single, want)
+	}
+
+	sensors := hmFixture(t, "lhm-desktop", "lhm_sensors.json")
+	var nvme *hmSensor
+	for i := range sensors {
+		if sensors[i].Parent == "/nvme/0" {
+			nvme = &sensors[i]
+		}
+	}
+	if nvme == nil {
+		t.Fatal("NVMe sensor missing from the decoded fixture")
+	}
+	if nvme.Value.Valid {
+		t.Errorf("null value decoded as %v", nvme.Value.Value)
+	}
+
+	for _, output := range []string{"", "[]", "not json"} {
+		if _, err := parseHMSensors([]byte(output)); err == nil {
+			t.Errorf("parseHMSensors(%q) succeeded", output)
+		}
+	}
+}
+
+func TestHMSensorMapping(t *testing.T) {
+	tests := []struct {
+		dir, file string
+		tempC     float64
+		freq      FrequencyData
+		load      float64
+		perCPU    int
+		packageW  float64
+		dramW     float64
+	}{
+		{
+			dir: "lhm-desktop", file: "lhm_sensors.json",
+			tempC: 89.5, // Core (Tctl/Tdie), not the Super I/O "CPU Core" reading
+			freq: FrequencyData{
+				CurrentMHz:    4127,
+				MinCurrentMHz: 4074,
+				MaxCurrentMHz: 4149,
+				PerCoreMHz:    []int{4149, 4149, 4099, 4124, 4149, 4074, 4149, 4124},
+				Sockets:       []SocketFrequency{{Name: "/amdcpu/0", Cores: 8, CurrentMHz: 4127}},
+			},
+			load:     98.7,
+			perCPU:   8,
+			packageW: 121.84,
+		},
+		{
+			dir: "ohm-laptop", file: "ohm_sensors.json",
+			tempC: 50, // CPU Package before the hotter-ranked cores
+			freq: FrequencyData{
+				CurrentMHz:    1097,
+				MinCurrentMHz: 798,
+				MaxCurrentMHz: 1995,
+				PerCoreMHz:    []int{798, 798, 1995, 798},
+				Sockets:       []SocketFrequency{{Name: "/intelcpu/0", Cores: 4, CurrentMHz: 1097}},
+			},
+			load:     6.25,
+			perCPU:   4,
+			packageW: 4.61,
+			dramW:    0.92,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			sensors := hmFixture(t, tt.dir, tt.file)
+
+			temp, err := cpuTempFromSensors(sensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if temp != tt.tempC {
+				t.Errorf("temperature = %v, want %v", temp, tt.tempC)
+			}
+
+			freq, err := frequencyFromSensors(sensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(freq, tt.freq) {
+				t.Errorf("frequency = %+v, want %+v", freq, tt.freq)
+			}
+
+			load, err := loadFromSensors(sensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if load.Percent != tt.load || len(load.PerCPU) != tt.perCPU {
+				t.Errorf("load = %+v, want %v%% over %d cores", load, tt.load, tt.perCPU)
+			}
+
+			power, err := powerFromSensors(sensors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if power.PackageW != tt.packageW || power.DRAMW != tt.dramW {
+				t.Errorf("power = %+v, want package %v W, DRAM %v W", power, tt.packageW, tt.dramW)
+			}
+		})
+	}
+}
+
+func TestHMTempSensorSelectors(t *testing.T) {
+	useFixtures(t, "ohm-laptop")
+	p := NewHardwareMonitorProvider()
+	temps, err := p.TempSensors(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, s := range temps {
+		got[s.Selector] = s.TempC
+	}
+	want := map[string]float64{
+		"wmi:OpenHardwareMonitor/intelcpu/0/CPU Core #1": 47,
+		"wmi:OpenHardwareMonitor/intelcpu/0/CPU Core #2": 45,
+		"wmi:OpenHardwareMonitor/intelcpu/0/CPU Core #3": 49,
+		"wmi:OpenHardwareMonitor/intelcpu/0/CPU Core #4": 46,
+		"wmi:OpenHardwareMonitor/intelcpu/0/CPU Package": 50,
+		"wmi:OpenHardwareMonitor/hdd/0/Temperature":      38,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("selectors = %v, want %v", got, want)
+	}
+
+	// Selectors pick one piece of hardware, or every one with a pattern.
+	m := &TempMapping{Selectors: []string{"wmi:OpenHardwareMonitor/hdd/*/Temperature"}, Rule: TempRuleMax}
+	temp, used, err := m.Apply(temps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 38 || len(used) != 1 {
+		t.Errorf("hdd mapping = %v from %v, want 38 from the drive only", temp, used)
+	}
+}
+
+// failingExecutor fails every command, like PowerShell once the hardware
+// monitor has exited.
+type failingExecutor struct{}
+
+func (failingExecutor) Run(ctx context.Context, command string) (string, error) {
+	return "", &ExecError{Kind: ErrNotFound, Command: command, Err: errors.New("Invalid namespace")}
+}
+
+// waitSearch waits for a background namespace search to finish.
+func waitSearch(t *testing.T, p *HardwareMonitorProvider) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		p.mu.Lock()
+		searching := p.searching
+		p.mu.Unlock()
+		if !searching {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background search did not finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHMRediscoversAfterMonitorExits(t *testing.T) {
+	useFixtures(t, "lhm-desktop")
+	p := NewHardwareMonitorProvider()
+	ctx := context.Background()
+	if _, err := p.get(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The monitor exits: the query fails and the namespace is forgotten.
+	SetExecutor(failingExecutor{})
+	p.mu.Lock()
+	p.fetched = time.Time{}
+	p.mu.Unlock()
+	if _, err := p.get(ctx); err == nil {
+		t.Fatal("get succeeded after the monitor exited")
+	}
+	waitSearch(t, p)
+	p.mu.Lock()
+	ns := p.namespace
+	p.mu.Unlock()
+	if ns != "" {
+		t.Fatalf("namespace %q kept after the monitor exited", ns)
+	}
+	if _, err := p.get(ctx); err == nil {
+		t.Fatal("get succeeded before the monitor came back")
+	}
+
+	// It comes back; once the retry interval is over a background search finds it.
+	useFixtures(t, "lhm-desktop")
+	p.mu.Lock()
+	p.searched = time.Now().Add(-discoveryRetry)
+	p.mu.Unlock()
+	if _, err := p.get(ctx); err == nil {
+		t.Fatal("get waited for the retry search")
+	}
+	waitSearch(t, p)
+	if _, err := p.get(ctx); err != nil {
+		t.Fatalf("get after the monitor came back: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package sensors
+
+import (
+	"context"
+	"runtime"
+)
+
+// LinuxProvider reads sensors from sysfs (hwmon, thermal_zone, cpufreq, powercap) and procfs.
+type LinuxProvider struct {
+	Temp     *SysfsTemperature
+	Freq     *SysfsFrequency
+	CPULoad  *ProcStatLoad
+	RAPL     *RAPLPower
+	Throttle *ThrottleCounters
+	MSR      *MSRReader
+	Cgroup   *CgroupCPU
+	PSI      *PSIReader
+	Fan      *SysfsFans
+	Supply   *SysfsPowerSupply
+}
+
+// NewLinuxProvider returns a provider reading the real /sys and /proc.
+func NewLinuxProvider() *LinuxProvider {
+	return &LinuxProvider{
+		Temp:     NewSysfsTemperature(),
+		Freq:     NewSysfsFrequency(),
+		CPULoad:  NewProcStatLoad(),
+		RAPL:     NewRAPLPower(),
+		Throttle: NewThrottleCounters(),
+		MSR:      NewMSRReader(),
+		Cgroup:   NewCgroupCPU(),
+		PSI:      NewPSIReader(),
+		Fan:      NewSysfsFans(),
+		Supply:   NewSysfsPowerSupply(),
+	}
+}
+
+func (p *LinuxProvider) Name() string { return "linux" }
+
+func (p *LinuxProvider) Available() bool { return runtime.GOOS == "linux" }
+
+// sysfs reads do not block, so ctx only matters for Load.
+func (p *LinuxProvider) Temperature(ctx context.Context) (float64, error) {
+	return p.Temp.CPUTemperature()
+}
+
+// TempSensors lists every hwmon temperature input and thermal zone.
+func (p *LinuxProvider) TempSensors(ctx context.Context) ([]NamedTemp, error) {
+	hwmon, err := p.Temp.HwmonSensors()
+	if err != nil {
+		return nil, err
+	}
+	zones, err := p.Temp.ThermalZones()
+	if err != nil {
+		return nil, err
+	}
+	var list []NamedTemp
+	for _, s := range append(hwmon, zones...) {
+		list = append(list, NamedTemp{Selector: s.Selector(), Path: s.Path, TempC: s.TempC})
+	}
+	return list, nil
+}
+
+func (p *LinuxProvider) Frequency(ctx context.Context) (FrequencyData, error) {
+	return p.Freq.CPUFrequency()
+}
+
+// Load is measured over the time since the previous call.
+func (p *LinuxProvider) Load(ctx context.Context) (LoadData, error) {
+	return p.CPULoad.Sample(ctx)
+}
+
+// Power is measured over the time since the previous call.
+func (p *LinuxProvider) Power(ctx context.Context) (PowerData, error) {
+	return p.RAPL.Power(ctx)
+}
+
+// ThrottleCounters reports kernel throttle events since the previous call.
+func (p *LinuxProvider) ThrottleCounters(ctx context.Context) (ThrottleData, error) {
+	return p.Throttle.Sample(ctx)
+}
+
+// ThermStatus reads IA32_(PACKAGE_)THERM_STATUS; it needs root.
+func (p *LinuxProvider) ThermStatus(ctx context.Context) (MSRData, error) {
+	return p.MSR.Read(ctx)
+}
+
+// CgroupThrottle reports CPU quota throttling of Cgroup.Path since the previous call.
+func (p *LinuxProvider) CgroupThrottle(ctx context.Context) (CgroupData, error) {
+	return p.Cgroup.Sample(ctx)
+}
+
+// Pressure reads /proc/pressure/cpu.
+func (p *LinuxProvider) Pressure(ctx context.Context) (PressureData, error) {
+	return p.PSI.CPU()
+}
+
+// Fans reads hwmon fan tachometers and their pwm duty cycles.
+func (p *LinuxProvider) Fans(ctx context.Context) (FanData, error) {
+	return p.Fan.Fans()
+}
+
+// PowerSupply reads the AC adapter and battery state.
+func (p *LinuxProvider) PowerSupply(ctx context.Context) (PowerSupplyData, error) {
+	return p.Supply.Read()
+}
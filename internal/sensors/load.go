@@ -1,27 +1,45 @@
 package sensors
 
 import (
+	"context"
 	"fmt"
-	"strconv"
 )
 
-// GetCPULoad fetches the current CPU load percentage (0-100).
-func GetCPULoad() (float64, error) {
+// Load fetches CPU utilization in percent (0-100).
+func (p *WMIProvider) Load(ctx context.Context) (LoadData, error) {
 	// Win32_Processor LoadPercentage is an instant snapshot.
 	// For better accuracy, we might want typeperf "\Processor(_Total)\% Processor Time"
 	// But Win32_Processor is faster/easier for this scope.
-	
-	cmd := "Get-CimInstance -ClassName Win32_Processor | Select-Object -ExpandProperty LoadPercentage | Measure-Object -Average | Select-Object -ExpandProperty Average"
-	
-	output, err := execPowerShell(cmd)
+	procs, err := p.processors.get(ctx)
 	if err != nil {
-		return 0, err
+		return LoadData{}, err
 	}
-	
-	load, err := strconv.ParseFloat(output, 64)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse load: %v", err)
+	return loadFromProcessors(procs)
+}
+
+// loadFromProcessors averages LoadPercentage across sockets, weighted by core count.
+// Sockets WMI has no sample for yet are skipped.
+func loadFromProcessors(procs []win32Processor) (LoadData, error) {
+	var data LoadData
+	var sum, weightSum float64
+
+	for _, proc := range procs {
+		if !proc.LoadPercentage.Valid {
+			continue
+		}
+		data.PerSocket = append(data.PerSocket, proc.LoadPercentage.Value)
+
+		weight := proc.NumberOfCores.Value
+		if weight <= 0 {
+			weight = 1
+		}
+		sum += proc.LoadPercentage.Value * weight
+		weightSum += weight
+	}
+
+	if weightSum == 0 {
+		return LoadData{}, fmt.Errorf("no processor reported a load percentage")
 	}
-	
-	return load, nil
+	data.Percent = sum / weightSum
+	return data, nil
 }
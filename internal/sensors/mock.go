@@ -0,0 +1,42 @@
+package sensors
+
+import (
+	"context"
+	"math/rand"
+)
+
+// MockProvider produces random but plausible readings. It is never auto-selected;
+// it only runs when --demo or --sensor-backend mock asks for it, and every signal
+// it produces is marked as mocked.
+type MockProvider struct{}
+
+func NewMockProvider() *MockProvider {
+	return &MockProvider{}
+}
+
+func (p *MockProvider) Name() string { return "mock" }
+
+func (p *MockProvider) Available() bool { return false }
+
+// Simulated marks this backend's output as synthetic.
+func (p *MockProvider) Simulated() bool { return true }
+
+// Temperature is a random value between 45.0 and 65.0, a realistic "normal" range.
+func (p *MockProvider) Temperature(ctx context.Context) (float64, error) {
+	return 45.0 + rand.Float64()*20.0, nil
+}
+
+func (p *MockProvider) Frequency(ctx context.Context) (FrequencyData, error) {
+	curr := 2800 + rand.Intn(400)
+	return FrequencyData{
+		CurrentMHz:    curr,
+		BaseMHz:       3000,
+		MaxMHz:        4200,
+		MinCurrentMHz: curr,
+		MaxCurrentMHz: curr,
+	}, nil
+}
+
+func (p *MockProvider) Load(ctx context.Context) (LoadData, error) {
+	return LoadData{Percent: 10.0 + rand.Float64()*30.0}, nil
+}
@@ -0,0 +1,329 @@
+package sensors
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDevRoot is where the msr driver exposes /dev/cpu/N/msr.
+const DefaultDevRoot = "/dev"
+
+// Model-specific register addresses (Intel SDM vol. 4).
+const (
+	msrIA32ThermStatus        = 0x19C
+	msrTemperatureTarget      = 0x1A2
+	msrIA32PackageThermStatus = 0x1B1
+)
+
+// IA32_THERM_STATUS / IA32_PACKAGE_THERM_STATUS bits. Even bits are live status,
+// the odd bit after each is its sticky log (set since the log was last cleared).
+const (
+	thermBitStatus          = 0
+	thermBitStatusLog       = 1
+	thermBitPROCHOT         = 2
+	thermBitPROCHOTLog      = 3
+	thermBitCritical        = 4
+	thermBitPowerLimit      = 10
+	thermBitPowerLimitLog   = 11
+	thermBitCurrentLimit    = 12 // Core register only
+	thermBitCurrentLimitLog = 13 // Core register only
+	thermBitReadingValid    = 31
+)
+
+// Log bits the reader clears after reading them when asked to, so that a set
+// log means the limit was hit since the previous read. The package register
+// has no current limit.
+const (
+	coreLogMask    = 1<<thermBitStatusLog | 1<<thermBitPROCHOTLog | 1<<thermBitPowerLimitLog | 1<<thermBitCurrentLimitLog
+	packageLogMask = 1<<thermBitStatusLog | 1<<thermBitPROCHOTLog | 1<<thermBitPowerLimitLog
+)
+
+// ThermStatus is a decoded IA32_THERM_STATUS or IA32_PACKAGE_THERM_STATUS value.
+type ThermStatus struct {
+	Thermal         bool // At or above the thermal control circuit (TCC) activation temperature
+	ThermalLog      bool
+	PROCHOT         bool // PROCHOT# or FORCEPR# asserted by something outside the core
+	PROCHOTLog      bool
+	Critical        bool
+	PowerLimit      bool
+	PowerLimitLog   bool
+	CurrentLimit    bool
+	CurrentLimitLog bool
+	ReadoutValid    bool
+	BelowTjMaxC     int // Digital readout: degrees below TjMax
+}
+
+// DecodeThermStatus decodes a raw thermal status register value.
+func DecodeThermStatus(raw uint64) ThermStatus {
+	bit := func(n uint) bool { return raw&(1<<n) != 0 }
+	return ThermStatus{
+		Thermal:         bit(thermBitStatus),
+		ThermalLog:      bit(thermBitStatusLog),
+		PROCHOT:         bit(thermBitPROCHOT),
+		PROCHOTLog:      bit(thermBitPROCHOTLog),
+		Critical:        bit(thermBitCritical),
+		PowerLimit:      bit(thermBitPowerLimit),
+		PowerLimitLog:   bit(thermBitPowerLimitLog),
+		CurrentLimit:    bit(thermBitCurrentLimit),
+		CurrentLimitLog: bit(thermBitCurrentLimitLog),
+		ReadoutValid:    bit(thermBitReadingValid),
+		BelowTjMaxC:     int((raw >> 16) & 0x7F), // Bits 22:16
+	}
+}
+
+// merge ORs status flags and keeps the hottest readout.
+func (t ThermStatus) merge(o ThermStatus) ThermStatus {
+	t.Thermal = t.Thermal || o.Thermal
+	t.ThermalLog = t.ThermalLog || o.ThermalLog
+	t.PROCHOT = t.PROCHOT || o.PROCHOT
+	t.PROCHOTLog = t.PROCHOTLog || o.PROCHOTLog
+	t.Critical = t.Critical || o.Critical
+	t.PowerLimit = t.PowerLimit || o.PowerLimit
+	t.PowerLimitLog = t.PowerLimitLog || o.PowerLimitLog
+	t.CurrentLimit = t.CurrentLimit || o.CurrentLimit
+	t.CurrentLimitLog = t.CurrentLimitLog || o.CurrentLimitLog
+	if o.ReadoutValid && (!t.ReadoutValid || o.BelowTjMaxC < t.BelowTjMaxC) {
+		t.ReadoutValid = true
+		t.BelowTjMaxC = o.BelowTjMaxC
+	}
+	return t
+}
+
+// MSRData is the thermal status of the whole machine, merged over all CPUs.
+type MSRData struct {
+	TjMaxC  int // 0 if MSR_TEMPERATURE_TARGET could not be read
+	Core    ThermStatus
+	Package ThermStatus
+	// LogsSinceLastRead is set when the log bits of the previous read were
+	// cleared, so the logs cover the time since then. Otherwise they may date
+	// from any time since boot and say nothing about the present.
+	LogsSinceLastRead bool
+}
+
+// Throttling reports whether the hardware says it is thermally limiting right now.
+func (d MSRData) Throttling() bool {
+	return d.Core.Thermal || d.Core.PROCHOT || d.Package.Thermal || d.Package.PROCHOT
+}
+
+// TempC converts the package digital readout to Celsius, the temperature the
+// CPU itself throttles on. ok is false when
+// TjMax or a valid readout is unavailable.
+func (d MSRData) TempC() (float64, bool) {
+	if d.TjMaxC == 0 || !d.Package.ReadoutValid {
+		return 0, false
+	}
+	return float64(d.TjMaxC - d.Package.BelowTjMaxC), true
+}
+
+// ThrottledSinceLastRead reports whether the thermal or PROCHOT logs show
+// throttling since the previous read, including throttling that ended before this one.
+func (d MSRData) ThrottledSinceLastRead() bool {
+	return d.LogsSinceLastRead && (d.Core.ThermalLog || d.Core.PROCHOTLog || d.Package.ThermalLog || d.Package.PROCHOTLog)
+}
+
+// LoggedReasons describes the logged limits that are no longer active. With
+// LogsSinceLastRead they were hit since the previous read, e.g. "throttled
+// since last read (PROCHOT, power limit)"; otherwise at some point since boot
+// or since the logs were last cleared, e.g. "logged since boot or last clear
+// (PROCHOT)".
+func (d MSRData) LoggedReasons() []string {
+	var limits []string
+	if (d.Core.PROCHOTLog || d.Package.PROCHOTLog) && !(d.Core.PROCHOT || d.Package.PROCHOT) {
+		limits = append(limits, "PROCHOT")
+	}
+	if (d.Core.ThermalLog || d.Package.ThermalLog) && !(d.Core.Thermal || d.Package.Thermal) {
+		limits = append(limits, "TjMax")
+	}
+	if (d.Core.PowerLimitLog || d.Package.PowerLimitLog) && !(d.Core.PowerLimit || d.Package.PowerLimit) {
+		limits = append(limits, "power limit")
+	}
+	if d.Core.CurrentLimitLog && !d.Core.CurrentLimit {
+		limits = append(limits, "current limit")
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	if !d.LogsSinceLastRead {
+		return []string{fmt.Sprintf("logged since boot or last clear (%s)", strings.Join(limits, ", "))}
+	}
+	return []string{fmt.Sprintf("throttled since last read (%s)", strings.Join(limits, ", "))}
+}
+
+// Reasons lists the currently active limits in plain words.
+func (d MSRData) Reasons() []string {
+	var reasons []string
+	if d.Core.PROCHOT || d.Package.PROCHOT {
+		reasons = append(reasons, "PROCHOT asserted")
+	}
+	if d.Core.Thermal || d.Package.Thermal {
+		reasons = append(reasons, "at TjMax (thermal control circuit active)")
+	}
+	if d.Core.Critical || d.Package.Critical {
+		reasons = append(reasons, "critical temperature")
+	}
+	if d.Core.PowerLimit || d.Package.PowerLimit {
+		reasons = append(reasons, "power limit")
+	}
+	if d.Core.CurrentLimit {
+		reasons = append(reasons, "current limit")
+	}
+	return reasons
+}
+
+// MSRReader reads thermal status MSRs through the msr driver (/dev/cpu/N/msr).
+// The driver needs root (or CAP_SYS_RAWIO), so the reader is optional: without
+// access it returns ErrAccessDenied and the signal is simply missing.
+// DevRoot can point at a directory of fake msr files for testing; a register is
+// read as 8 little-endian bytes at the offset equal to its address.
+//
+// By default the reader only reads. With ClearLogs, the sticky log bits are
+// cleared after each read by writing the register back with them zeroed; the
+// other bits are read-only. The next read's logs then cover just the time in
+// between. Clearing is opt-in: the kernel's thermal interrupt handler manages
+// the same bits for the thermal_throttle counters, and writes through the msr
+// driver are logged by the kernel.
+type MSRReader struct {
+	DevRoot   string
+	ClearLogs bool
+
+	mu      sync.Mutex
+	cleared bool // The previous read cleared the logs of every CPU
+}
+
+// NewMSRReader returns a read-only reader rooted at DefaultDevRoot.
+func NewMSRReader() *MSRReader {
+	return &MSRReader{DevRoot: DefaultDevRoot}
+}
+
+// Read merges the core and package thermal status of every CPU. A CPU whose
+// registers cannot be read is skipped; Read fails only when none can be.
+func (m *MSRReader) Read(ctx context.Context) (MSRData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(m.DevRoot, "cpu", "[0-9]*", "msr"))
+	if err != nil {
+		return MSRData{}, err
+	}
+	if len(files) == 0 {
+		return MSRData{}, &ExecError{Kind: ErrNotFound, Err: fmt.Errorf("no msr devices under %s (is the msr module loaded?)", m.DevRoot)}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return msrCPU(files[i]) < msrCPU(files[j])
+	})
+
+	var data MSRData
+	var firstErr error
+	read, cleared := 0, m.ClearLogs
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return MSRData{}, err
+		}
+
+		regs, err := readMSRs(path, msrIA32ThermStatus, msrIA32PackageThermStatus)
+		if err != nil {
+			// An offline CPU or one broken register must not hide the others.
+			if firstErr == nil {
+				firstErr = err
+			}
+			cleared = false
+			continue
+		}
+		if m.ClearLogs && !clearMSRLogs(path, regs) {
+			cleared = false
+		}
+		core, pkg := DecodeThermStatus(regs[0]), DecodeThermStatus(regs[1])
+		read++
+		if read == 1 {
+			data.Core, data.Package = core, pkg
+			// TjMax lives in bits 23:16 of MSR_TEMPERATURE_TARGET. Some CPUs lack the
+			// register; the status bits are still useful without it.
+			if target, err := readMSRs(path, msrTemperatureTarget); err == nil {
+				data.TjMaxC = int((target[0] >> 16) & 0xFF)
+			}
+			continue
+		}
+		data.Core = data.Core.merge(core)
+		data.Package = data.Package.merge(pkg)
+	}
+	if read == 0 {
+		m.cleared = false
+		return MSRData{}, firstErr
+	}
+
+	data.LogsSinceLastRead = m.cleared
+	m.cleared = cleared
+	return data, nil
+}
+
+// clearMSRLogs writes the thermal status registers back with their log bits
+// zeroed, if any is set. It reports whether the logs are clear afterwards.
+func clearMSRLogs(path string, regs []uint64) bool {
+	if regs[0]&coreLogMask == 0 && regs[1]&packageLogMask == 0 {
+		return true
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 8)
+	for _, w := range []struct {
+		reg  int64
+		val  uint64
+		mask uint64
+	}{
+		{msrIA32ThermStatus, regs[0], coreLogMask},
+		{msrIA32PackageThermStatus, regs[1], packageLogMask},
+	} {
+		if w.val&w.mask == 0 {
+			continue
+		}
+		binary.LittleEndian.PutUint64(buf, w.val&^w.mask)
+		if _, err := f.WriteAt(buf, w.reg); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// readMSRs reads 64-bit registers from one msr device file.
+func readMSRs(path string, regs ...int64) ([]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil, &ExecError{Kind: ErrAccessDenied, Err: fmt.Errorf("%s needs root: %v", path, err)}
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make([]uint64, len(regs))
+	buf := make([]byte, 8)
+	for i, reg := range regs {
+		if _, err := f.ReadAt(buf, reg); err != nil {
+			// The driver answers EIO for registers the CPU does not implement.
+			return nil, &ExecError{Kind: ErrNotFound, Err: fmt.Errorf("read MSR 0x%X from %s: %v", reg, path, err)}
+		}
+		values[i] = binary.LittleEndian.Uint64(buf)
+	}
+	return values, nil
+}
+
+// msrCPU extracts N from .../cpu/N/msr.
+func msrCPU(path string) int {
+	n, err := strconv.Atoi(filepath.Base(filepath.Dir(path)))
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,187 @@
+package sensors
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// writeMSRFile creates a fake /dev/cpu/N/msr holding the given registers.
+func writeMSRFile(t *testing.T, devRoot string, cpu int, regs map[int64]uint64) string {
+	t.Helper()
+	path := filepath.Join(devRoot, "cpu", strconv.Itoa(cpu), "msr")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, msrIA32PackageThermStatus+8)
+	for reg, v := range regs {
+		binary.LittleEndian.PutUint64(buf[reg:], v)
+	}
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// thermStatus builds a status register value from set bits and a readout.
+func thermStatus(belowTjMax uint64, bits ...uint) uint64 {
+	v := belowTjMax<<16 | 1<<thermBitReadingValid
+	for _, b := range bits {
+		v |= 1 << b
+	}
+	return v
+}
+
+func TestDecodeThermStatus(t *testing.T) {
+	got := DecodeThermStatus(thermStatus(12, thermBitPROCHOT, thermBitPowerLimitLog))
+	want := ThermStatus{PROCHOT: true, PowerLimitLog: true, ReadoutValid: true, BelowTjMaxC: 12}
+	if got != want {
+		t.Errorf("DecodeThermStatus = %+v, want %+v", got, want)
+	}
+}
+
+func TestMSRReaderMergesCPUs(t *testing.T) {
+	dev := t.TempDir()
+	writeMSRFile(t, dev, 0, map[int64]uint64{
+		msrIA32ThermStatus:        thermStatus(30),
+		msrTemperatureTarget:      100 << 16,
+		msrIA32PackageThermStatus: thermStatus(25),
+	})
+	writeMSRFile(t, dev, 1, map[int64]uint64{
+		msrIA32ThermStatus:        thermStatus(8, thermBitPowerLimit),
+		msrIA32PackageThermStatus: thermStatus(5, thermBitPROCHOT),
+	})
+	// A CPU whose registers cannot be read is skipped.
+	if err := os.MkdirAll(filepath.Join(dev, "cpu", "2"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dev, "cpu", "2", "msr"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &MSRReader{DevRoot: dev}
+	data, err := m.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TjMaxC != 100 {
+		t.Errorf("TjMaxC = %d, want 100", data.TjMaxC)
+	}
+	if temp, ok := data.TempC(); !ok || temp != 95 {
+		t.Errorf("TempC() = %v, %v, want the hottest package readout, 95", temp, ok)
+	}
+	if !data.Throttling() {
+		t.Error("PROCHOT on CPU 1 not reported as throttling")
+	}
+	want := []string{"PROCHOT asserted", "power limit"}
+	if got := data.Reasons(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Reasons() = %v, want %v", got, want)
+	}
+}
+
+func TestMSRReaderNoReadableCPU(t *testing.T) {
+	dev := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dev, "cpu", "0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dev, "cpu", "0", "msr"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&MSRReader{DevRoot: dev}).Read(context.Background()); err == nil {
+		t.Error("Read succeeded without a readable CPU")
+	}
+}
+
+func TestMSRReaderClearsLogs(t *testing.T) {
+	dev := t.TempDir()
+	path := writeMSRFile(t, dev, 0, map[int64]uint64{
+		msrIA32ThermStatus:        thermStatus(40, thermBitStatusLog),
+		msrIA32PackageThermStatus: thermStatus(40, thermBitPROCHOTLog, thermBitPowerLimitLog),
+	})
+	m := &MSRReader{DevRoot: dev, ClearLogs: true}
+	ctx := context.Background()
+
+	// The first read's logs may be from before tta started.
+	first, err := m.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.LogsSinceLastRead || first.ThrottledSinceLastRead() {
+		t.Errorf("first read reports logs since the last read: %+v", first)
+	}
+	want := []string{"logged since boot or last clear (PROCHOT, TjMax, power limit)"}
+	if got := first.LoggedReasons(); !reflect.DeepEqual(got, want) {
+		t.Errorf("first LoggedReasons() = %v, want %v", got, want)
+	}
+	regs, err := readMSRs(path, msrIA32ThermStatus, msrIA32PackageThermStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regs[0]&coreLogMask != 0 || regs[1]&packageLogMask != 0 {
+		t.Fatalf("logs not cleared: %#x %#x", regs[0], regs[1])
+	}
+	if regs[0] != thermStatus(40) {
+		t.Errorf("clearing changed other bits: %#x", regs[0])
+	}
+
+	// Nothing happened in between.
+	quiet, err := m.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !quiet.LogsSinceLastRead || quiet.ThrottledSinceLastRead() {
+		t.Errorf("quiet read: %+v", quiet)
+	}
+
+	// PROCHOT came and went between two reads.
+	writeMSRFile(t, dev, 0, map[int64]uint64{
+		msrIA32ThermStatus:        thermStatus(40),
+		msrIA32PackageThermStatus: thermStatus(40, thermBitPROCHOTLog),
+	})
+	third, err := m.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !third.ThrottledSinceLastRead() || third.Throttling() {
+		t.Errorf("third read: throttled since last read %v, throttling now %v", third.ThrottledSinceLastRead(), third.Throttling())
+	}
+	want = []string{"throttled since last read (PROCHOT)"}
+	if got := third.LoggedReasons(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LoggedReasons() = %v, want %v", got, want)
+	}
+}
+
+func TestMSRReaderReadOnlyByDefault(t *testing.T) {
+	dev := t.TempDir()
+	status := thermStatus(40, thermBitPROCHOTLog)
+	path := writeMSRFile(t, dev, 0, map[int64]uint64{
+		msrIA32ThermStatus:        status,
+		msrIA32PackageThermStatus: status,
+	})
+	m := NewMSRReader()
+	m.DevRoot = dev
+	for i := 0; i < 2; i++ {
+		data, err := m.Read(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.LogsSinceLastRead || data.ThrottledSinceLastRead() {
+			t.Errorf("read %d: logs taken as since the last read without clearing: %+v", i+1, data)
+		}
+		want := []string{"logged since boot or last clear (PROCHOT)"}
+		if got := data.LoggedReasons(); !reflect.DeepEqual(got, want) {
+			t.Errorf("read %d: LoggedReasons() = %v, want %v", i+1, got, want)
+		}
+	}
+	regs, err := readMSRs(path, msrIA32ThermStatus, msrIA32PackageThermStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regs[0] != status || regs[1] != status {
+		t.Errorf("default reader wrote the registers: %#x %#x", regs[0], regs[1])
+	}
+}
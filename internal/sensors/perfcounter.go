@@ -0,0 +1,482 @@
+package sensors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Get-Counter query of the performance-counter backend. Rate counters need two
+// raw samples, so the query takes about a second (the default sample
+// interval); processor and thermal zone counters are sampled in the same call
+// to stay within one tick. A machine without ACPI thermal zones has no
+// "Thermal Zone Information" set, so missing counters are skipped rather
+// than failing the whole query.
+// Counter paths are only recognized in English: on a localized Windows the
+// query returns nothing and auto-selection falls back to the WMI backend.
+const (
+	counterQuery = `Get-Counter -ErrorAction SilentlyContinue -Counter ` +
+		`'\Processor Information(*)\% Processor Performance','\Processor Information(*)\% Processor Utility',` +
+		`'\Processor Information(*)\Processor Frequency','\Thermal Zone Information(*)\Temperature',` +
+		`'\Thermal Zone Information(*)\% Passive Limit','\Thermal Zone Information(*)\Throttle Reasons' | ` +
+		`Select-Object -ExpandProperty CounterSamples | Select-Object Path,InstanceName,CookedValue | ConvertTo-Json -Compress`
+
+	// perfCounterProbe checks once that the counter sets exist under their English names.
+	perfCounterProbe = `Get-Counter -ListSet 'Processor Information' -ErrorAction Stop | Select-Object -ExpandProperty CounterSetName`
+)
+
+// Counter set and counter names as they appear, lowercased, in a sample path.
+const (
+	setProcessor   = "processor information"
+	setThermalZone = "thermal zone information"
+
+	counterPerformance  = "% processor performance"
+	counterUtility      = "% processor utility"
+	counterFrequency    = "processor frequency"
+	counterTemperature  = "temperature"
+	counterPassiveLimit = "% passive limit"
+	counterThrottle     = "throttle reasons"
+)
+
+// PerfCounterProvider reads sensors on Windows from performance counters.
+// Win32_Processor.CurrentClockSpeed stays at the nominal clock on most modern
+// CPUs; "% Processor Performance" is the actual clock relative to nominal,
+// including turbo, so it shows throttling WMI cannot.
+type PerfCounterProvider struct {
+	counters counterCache // Samples shared by Frequency, Load, Temperature and ThermalZones
+
+	probeOnce sync.Once
+	probed    bool
+}
+
+// NewPerfCounterProvider returns the Windows performance-counter backend.
+func NewPerfCounterProvider() *PerfCounterProvider {
+	return &PerfCounterProvider{counters: counterCache{query: counterQuery}}
+}
+
+func (p *PerfCounterProvider) Name() string { return "perfcounter" }
+
+// Available also checks that the counters can be found by name, which is not
+// the case on localized Windows installations.
+func (p *PerfCounterProvider) Available() bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	if _, err := exec.LookPath("powershell"); err != nil {
+		return false
+	}
+	p.probeOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), AvailableTimeout)
+		defer cancel()
+		out, err := execPowerShell(ctx, perfCounterProbe)
+		p.probed = err == nil && strings.TrimSpace(out) != ""
+	})
+	return p.probed
+}
+
+// Frequency computes the effective clock: the nominal "Processor Frequency"
+// scaled by "% Processor Performance".
+func (p *PerfCounterProvider) Frequency(ctx context.Context) (FrequencyData, error) {
+	samples, err := p.counters.get(ctx)
+	if err != nil {
+		return FrequencyData{}, err
+	}
+	return frequencyFromCounters(samples)
+}
+
+// Load returns "% Processor Utility", the utilization Task Manager shows. It is
+// scaled by the performance counter and so exceeds 100% under turbo; it is capped.
+func (p *PerfCounterProvider) Load(ctx context.Context) (LoadData, error) {
+	samples, err := p.counters.get(ctx)
+	if err != nil {
+		return LoadData{}, err
+	}
+	return loadFromCounters(samples)
+}
+
+// Temperature returns the hottest ACPI thermal zone in Celsius.
+func (p *PerfCounterProvider) Temperature(ctx context.Context) (float64, error) {
+	zones, err := p.ThermalZones(ctx)
+	if err != nil {
+		return 0, err
+	}
+	hottest := math.Inf(-1)
+	for _, z := range zones.Zones {
+		if z.HasTemp && z.TempC > hottest {
+			hottest = z.TempC
+		}
+	}
+	if math.IsInf(hottest, -1) {
+		return 0, &ExecError{Kind: ErrNotFound, Command: counterQuery, Err: fmt.Errorf("no thermal zone reported a temperature")}
+	}
+	return hottest, nil
+}
+
+// ThermalZones returns every ACPI thermal zone with its passive cooling limit.
+func (p *PerfCounterProvider) ThermalZones(ctx context.Context) (ZoneData, error) {
+	samples, err := p.counters.get(ctx)
+	if err != nil {
+		return ZoneData{}, err
+	}
+	data, err := zonesFromCounters(samples)
+	if err != nil {
+		return ZoneData{}, &ExecError{Kind: ErrNotFound, Command: counterQuery, Err: err}
+	}
+	return data, nil
+}
+
+// TempSensors lists the thermal zones, e.g. "perfcounter:zone/\_TZ.CPUZ".
+func (p *PerfCounterProvider) TempSensors(ctx context.Context) ([]NamedTemp, error) {
+	zones, err := p.ThermalZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []NamedTemp
+	for _, z := range zones.Zones {
+		if z.HasTemp {
+			list = append(list, NamedTemp{Selector: "perfcounter:zone/" + z.Name, TempC: z.TempC})
+		}
+	}
+	return list, nil
+}
+
+// counterSample is one entry of Get-Counter's CounterSamples.
+type counterSample struct {
+	Path         string
+	InstanceName string
+	CookedValue  cimNumber
+}
+
+// Set returns the lowercased counter set name, e.g. "processor information".
+func (s counterSample) Set() string {
+	path := strings.ToLower(s.Path)
+	open := strings.Index(path, "(")
+	if open < 0 {
+		return ""
+	}
+	return path[strings.LastIndex(path[:open], `\`)+1 : open]
+}
+
+// Counter returns the lowercased counter name, the last element of the path.
+// Thermal zone instances contain backslashes themselves ("\_TZ.CPUZ"), so the
+// name is taken after the closing parenthesis of the instance.
+func (s counterSample) Counter() string {
+	path := strings.ToLower(s.Path)
+	if i := strings.LastIndex(path, `)\`); i >= 0 {
+		return path[i+2:]
+	}
+	return path[strings.LastIndex(path, `\`)+1:]
+}
+
+// parseCounterSamples decodes Get-Counter output converted to JSON, accepting
+// both a single object and an array.
+func parseCounterSamples(output []byte) ([]counterSample, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, fmt.Errorf("no counter samples returned")
+	}
+
+	var samples []counterSample
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &samples); err != nil {
+			return nil, fmt.Errorf("failed to parse counter samples: %v", err)
+		}
+	} else {
+		var sample counterSample
+		if err := json.Unmarshal(output, &sample); err != nil {
+			return nil, fmt.Errorf("failed to parse counter samples: %v", err)
+		}
+		samples = append(samples, sample)
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no counter samples returned")
+	}
+	return samples, nil
+}
+
+// counterInstance is one "Processor Information" instance: "_Total" for the
+// machine, "S,_Total" for socket S, and "S,C" for logical processor C on socket S.
+type counterInstance struct {
+	name   string
+	socket int // -1 for the machine total
+	cpu    int // -1 for a total
+	values map[string]float64
+}
+
+func (in *counterInstance) isTotal() bool  { return in.socket < 0 }
+func (in *counterInstance) isSocket() bool { return in.socket >= 0 && in.cpu < 0 }
+
+// effectiveMHz is the nominal clock scaled by the performance percentage.
+func (in *counterInstance) effectiveMHz() (int, bool) {
+	perf, ok1 := in.values[counterPerformance]
+	nominal, ok2 := in.values[counterFrequency]
+	if !ok1 || !ok2 || nominal <= 0 {
+		return 0, false
+	}
+	return int(math.Round(nominal * perf / 100)), true
+}
+
+// groupProcessorSamples collects the samples of each instance. Instances whose
+// name does not follow the processor scheme are ignored.
+func groupProcessorSamples(samples []counterSample) []*counterInstance {
+	byName := map[string]*counterInstance{}
+	var instances []*counterInstance
+	for _, s := range samples {
+		if !s.CookedValue.Valid || s.Set() != setProcessor {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(s.InstanceName))
+		in, ok := byName[name]
+		if !ok {
+			socket, cpu, valid := parseProcessorInstance(name)
+			if !valid {
+				continue
+			}
+			in = &counterInstance{name: name, socket: socket, cpu: cpu, values: map[string]float64{}}
+			byName[name] = in
+			instances = append(instances, in)
+		}
+		in.values[s.Counter()] = s.CookedValue.Value
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		a, b := instances[i], instances[j]
+		if a.socket != b.socket {
+			return a.socket < b.socket
+		}
+		return a.cpu < b.cpu
+	})
+	return instances
+}
+
+// parseProcessorInstance splits a lowercased instance name into socket and
+// logical processor, -1 standing for "_total".
+func parseProcessorInstance(name string) (socket, cpu int, ok bool) {
+	if name == "_total" {
+		return -1, -1, true
+	}
+	s, c, found := strings.Cut(name, ",")
+	if !found {
+		return 0, 0, false
+	}
+	socket, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, 0, false
+	}
+	if c == "_total" {
+		return socket, -1, true
+	}
+	cpu, err = strconv.Atoi(c)
+	if err != nil {
+		return 0, 0, false
+	}
+	return socket, cpu, true
+}
+
+// frequencyFromCounters derives clocks from "Processor Information" samples.
+// The machine total gives the average, logical processors the per-core spread.
+// The counters have no notion of physical cores, so sockets only get Threads.
+func frequencyFromCounters(samples []counterSample) (FrequencyData, error) {
+	var data FrequencyData
+	var total *counterInstance
+	var socketIDs []int
+	threads := map[int]int{}
+
+	for _, in := range groupProcessorSamples(samples) {
+		mhz, ok := in.effectiveMHz()
+		if !ok {
+			continue
+		}
+		switch {
+		case in.isTotal():
+			total = in
+		case in.isSocket():
+			socketIDs = append(socketIDs, in.socket)
+			data.Sockets = append(data.Sockets, SocketFrequency{
+				Name:       fmt.Sprintf("Socket %d", in.socket),
+				CurrentMHz: mhz,
+				BaseMHz:    int(in.values[counterFrequency]),
+			})
+		default:
+			threads[in.socket]++
+			if len(data.PerCoreMHz) == 0 || mhz < data.MinCurrentMHz {
+				data.MinCurrentMHz = mhz
+			}
+			if mhz > data.MaxCurrentMHz {
+				data.MaxCurrentMHz = mhz
+			}
+			data.PerCoreMHz = append(data.PerCoreMHz, mhz)
+		}
+	}
+
+	if total == nil {
+		return FrequencyData{}, fmt.Errorf("no processor performance counters for _Total")
+	}
+	data.CurrentMHz, _ = total.effectiveMHz()
+	// "Processor Frequency" is the nominal clock. There is no counter for the
+	// turbo limit; the performance percentage simply goes above 100.
+	data.BaseMHz = int(total.values[counterFrequency])
+	for i, id := range socketIDs {
+		data.Sockets[i].Threads = threads[id]
+	}
+	if len(data.PerCoreMHz) == 0 {
+		data.MinCurrentMHz = data.CurrentMHz
+		data.MaxCurrentMHz = data.CurrentMHz
+	}
+	return data, nil
+}
+
+// loadFromCounters reads "% Processor Utility" for the machine, each socket and
+// each logical processor.
+func loadFromCounters(samples []counterSample) (LoadData, error) {
+	var data LoadData
+	found := false
+	for _, in := range groupProcessorSamples(samples) {
+		util, ok := in.values[counterUtility]
+		if !ok {
+			continue
+		}
+		util = math.Min(util, 100)
+		switch {
+		case in.isTotal():
+			data.Percent = util
+			found = true
+		case in.isSocket():
+			data.PerSocket = append(data.PerSocket, util)
+		default:
+			data.PerCPU = append(data.PerCPU, util)
+		}
+	}
+	if !found {
+		return LoadData{}, fmt.Errorf("no processor utility counter for _Total")
+	}
+	return data, nil
+}
+
+// ThermalZone is one ACPI thermal zone as reported by the
+// "Thermal Zone Information" counters.
+type ThermalZone struct {
+	Name            string
+	TempC           float64
+	HasTemp         bool
+	PassiveLimit    float64 // Percent of full speed passive cooling allows; 100 is unrestricted
+	ThrottleReasons uint32  // Nonzero while the zone throttles the processors
+}
+
+// Limited reports whether the zone is holding the processors back.
+func (z ThermalZone) Limited() bool {
+	return z.PassiveLimit < 100 || z.ThrottleReasons != 0
+}
+
+// ZoneData holds every thermal zone of the machine.
+type ZoneData struct {
+	Zones []ThermalZone
+}
+
+// PassiveLimit is the tightest limit of any zone, 100 when none is limiting.
+func (d ZoneData) PassiveLimit() float64 {
+	limit := 100.0
+	for _, z := range d.Zones {
+		limit = math.Min(limit, z.PassiveLimit)
+	}
+	return limit
+}
+
+// Reasons describes each limiting zone, e.g. "\_TZ.CPUZ passive limit 60%".
+func (d ZoneData) Reasons() []string {
+	var reasons []string
+	for _, z := range d.Zones {
+		if !z.Limited() {
+			continue
+		}
+		reason := fmt.Sprintf("%s passive limit %.0f%%", z.Name, z.PassiveLimit)
+		if z.ThrottleReasons != 0 {
+			reason = fmt.Sprintf("%s, throttle reasons 0x%x", reason, z.ThrottleReasons)
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons
+}
+
+// zonesFromCounters groups "Thermal Zone Information" samples by zone. The
+// Temperature counter is in kelvin.
+func zonesFromCounters(samples []counterSample) (ZoneData, error) {
+	byName := map[string]*ThermalZone{}
+	var names []string
+	for _, s := range samples {
+		if !s.CookedValue.Valid || s.Set() != setThermalZone {
+			continue
+		}
+		name := strings.TrimSpace(s.InstanceName)
+		z, ok := byName[name]
+		if !ok {
+			z = &ThermalZone{Name: name, PassiveLimit: 100}
+			byName[name] = z
+			names = append(names, name)
+		}
+		switch s.Counter() {
+		case counterTemperature:
+			z.TempC = s.CookedValue.Value - 273.15
+			z.HasTemp = true
+		case counterPassiveLimit:
+			z.PassiveLimit = s.CookedValue.Value
+		case counterThrottle:
+			z.ThrottleReasons = uint32(s.CookedValue.Value)
+		}
+	}
+	if len(names) == 0 {
+		return ZoneData{}, fmt.Errorf("no thermal zone counters returned")
+	}
+	sort.Strings(names)
+	var data ZoneData
+	for _, name := range names {
+		data.Zones = append(data.Zones, *byName[name])
+	}
+	return data, nil
+}
+
+// counterCacheTTL lets the reads sharing a Get-Counter query within one tick use
+// a single result.
+const counterCacheTTL = time.Second
+
+// counterCache holds the most recent result of one Get-Counter query.
+type counterCache struct {
+	query string
+
+	mu      sync.Mutex
+	fetched time.Time
+	samples []counterSample
+}
+
+// get returns cached samples if fresh, otherwise runs the query.
+// Concurrent callers wait on the mutex and reuse the first caller's result.
+func (c *counterCache) get(ctx context.Context) ([]counterSample, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.samples != nil && time.Since(c.fetched) < counterCacheTTL {
+		return c.samples, nil
+	}
+
+	output, err := execPowerShell(ctx, c.query)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := parseCounterSamples([]byte(output))
+	if err != nil {
+		return nil, parseError(c.query, err)
+	}
+
+	c.samples = samples
+	c.fetched = time.Now()
+	return samples, nil
+}
@@ -0,0 +1,182 @@
+package sensors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCounterSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []counterSample
+		wantErr bool
+	}{
+		{
+			name:   "single object",
+			output: `{"Path":"\\\\pc\\processor information(_total)\\processor frequency","InstanceName":"_total","CookedValue":2592}`,
+			want: []counterSample{{
+				Path:         `\\pc\processor information(_total)\processor frequency`,
+				InstanceName: "_total",
+				CookedValue:  cimNumber{Value: 2592, Valid: true},
+			}},
+		},
+		{
+			name: "array",
+			output: `[{"Path":"\\\\pc\\processor information(_total)\\% processor performance","InstanceName":"_total","CookedValue":61.48},` +
+				`{"Path":"\\\\pc\\thermal zone information(\\_tz.cpuz)\\temperature","InstanceName":"\\_tz.cpuz","CookedValue":"369"}]`,
+			want: []counterSample{
+				{Path: `\\pc\processor information(_total)\% processor performance`, InstanceName: "_total", CookedValue: cimNumber{Value: 61.48, Valid: true}},
+				{Path: `\\pc\thermal zone information(\_tz.cpuz)\temperature`, InstanceName: `\_tz.cpuz`, CookedValue: cimNumber{Value: 369, Valid: true}},
+			},
+		},
+		{name: "empty output", output: "  \r\n", wantErr: true},
+		{name: "empty array", output: "[]", wantErr: true},
+		{name: "not JSON", output: "Get-Counter : The specified object was not found", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCounterSamples([]byte(tt.output))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseCounterSamples() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCounterSamples() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCounterSampleNames(t *testing.T) {
+	tests := []struct {
+		path, set, counter string
+	}{
+		{`\\pc\processor information(0,3)\% processor utility`, setProcessor, counterUtility},
+		{`\\PC\Processor Information(_Total)\Processor Frequency`, setProcessor, counterFrequency},
+		{`\\pc\thermal zone information(\_tz.cpuz)\% passive limit`, setThermalZone, counterPassiveLimit},
+		{`\\pc\thermal zone information(\_tz.tz01)\temperature`, setThermalZone, counterTemperature},
+	}
+	for _, tt := range tests {
+		s := counterSample{Path: tt.path}
+		if got := s.Set(); got != tt.set {
+			t.Errorf("Set(%q) = %q, want %q", tt.path, got, tt.set)
+		}
+		if got := s.Counter(); got != tt.counter {
+			t.Errorf("Counter(%q) = %q, want %q", tt.path, got, tt.counter)
+		}
+	}
+}
+
+func TestFrequencyFromCounters(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    FrequencyData
+		perCore int
+	}{
+		{
+			fixture: "perfcounter-laptop",
+			want: FrequencyData{
+				CurrentMHz:    1594,
+				BaseMHz:       2592,
+				MinCurrentMHz: 1506,
+				MaxCurrentMHz: 1684,
+				Sockets:       []SocketFrequency{{Name: "Socket 0", Threads: 12, CurrentMHz: 1594, BaseMHz: 2592}},
+			},
+			perCore: 12,
+		},
+		{
+			fixture: "perfcounter-workstation",
+			want: FrequencyData{
+				CurrentMHz:    2755,
+				BaseMHz:       2100,
+				MinCurrentMHz: 2561,
+				MaxCurrentMHz: 2913,
+				Sockets: []SocketFrequency{
+					{Name: "Socket 0", Threads: 4, CurrentMHz: 2812, BaseMHz: 2100},
+					{Name: "Socket 1", Threads: 4, CurrentMHz: 2699, BaseMHz: 2100},
+				},
+			},
+			perCore: 8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			samples, err := parseCounterSamples(readFixture(t, tt.fixture, "counters.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := frequencyFromCounters(samples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.PerCoreMHz) != tt.perCore {
+				t.Errorf("got %d per-core clocks, want %d", len(got.PerCoreMHz), tt.perCore)
+			}
+			got.PerCoreMHz = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("frequencyFromCounters() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrequencyFromCountersWithoutTotal(t *testing.T) {
+	samples := []counterSample{
+		{Path: `\\pc\processor information(0,0)\% processor performance`, InstanceName: "0,0", CookedValue: cimNumber{Value: 90, Valid: true}},
+		{Path: `\\pc\processor information(0,0)\processor frequency`, InstanceName: "0,0", CookedValue: cimNumber{Value: 3000, Valid: true}},
+	}
+	if _, err := frequencyFromCounters(samples); err == nil {
+		t.Error("frequencyFromCounters() succeeded without a _Total instance")
+	}
+}
+
+func TestZonesFromCounters(t *testing.T) {
+	samples, err := parseCounterSamples(readFixture(t, "perfcounter-laptop", "counters.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones, err := zonesFromCounters(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kelvin := 273.15
+	want := []ThermalZone{
+		{Name: `\_tz.batz`, TempC: 310 - kelvin, HasTemp: true, PassiveLimit: 100},
+		{Name: `\_tz.cpuz`, TempC: 369 - kelvin, HasTemp: true, PassiveLimit: 60, ThrottleReasons: 1},
+	}
+	if !reflect.DeepEqual(zones.Zones, want) {
+		t.Errorf("zones = %+v, want %+v", zones.Zones, want)
+	}
+	if got := zones.PassiveLimit(); got != 60 {
+		t.Errorf("PassiveLimit() = %v, want 60", got)
+	}
+
+	// The workstation has no ACPI thermal zones, only processor counters.
+	samples, err = parseCounterSamples(readFixture(t, "perfcounter-workstation", "counters.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zones, err := zonesFromCounters(samples); err == nil {
+		t.Errorf("zonesFromCounters() = %+v from processor counters only", zones)
+	}
+}
+
+func TestLoadFromCounters(t *testing.T) {
+	samples, err := parseCounterSamples(readFixture(t, "perfcounter-workstation", "counters.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	load, err := loadFromCounters(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if load.Percent != 100 || len(load.PerSocket) != 2 || len(load.PerCPU) != 8 {
+		t.Errorf("load = %+v, want 100%% with 2 sockets and 8 CPUs", load)
+	}
+}
@@ -0,0 +1,155 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReplaySpeed controls how fast a TracePlayer hands out snapshots.
+type ReplaySpeed struct {
+	Factor float64 // 1 plays in real time, 10 ten times faster; 0 means no waiting at all
+	Step   bool    // Wait for a step signal before every snapshot instead of a timer
+}
+
+// ParseReplaySpeed accepts "original", "step", "max" or a factor such as "10x" or "0.5".
+func ParseReplaySpeed(s string) (ReplaySpeed, error) {
+	switch s {
+	case "", "original":
+		return ReplaySpeed{Factor: 1}, nil
+	case "step":
+		return ReplaySpeed{Step: true}, nil
+	case "max":
+		return ReplaySpeed{Factor: 0}, nil
+	}
+	factor, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || factor <= 0 {
+		return ReplaySpeed{}, fmt.Errorf("invalid replay speed %q (use original, step, max or a factor like 10x)", s)
+	}
+	return ReplaySpeed{Factor: factor}, nil
+}
+
+// TracePlayer hands out the snapshots of a trace, paced by the gaps between
+// their timestamps. Snapshots keep their recorded timestamps unless StartAt is
+// set, so the state machine sees the original timing at any playback speed.
+type TracePlayer struct {
+	Speed ReplaySpeed
+	// Steps delivers one value per snapshot in step mode (e.g. one per Enter key).
+	Steps <-chan struct{}
+	// Loop restarts the trace at the end instead of returning io.EOF.
+	Loop bool
+	// StartAt, when set, shifts every timestamp so the first snapshot lands on it.
+	StartAt time.Time
+
+	trace  []*Snapshot
+	pos    int
+	offset time.Duration // Added to recorded timestamps (rebasing and looping)
+	last   time.Time     // Shifted timestamp of the previous snapshot
+}
+
+// NewTracePlayer plays trace at the given speed.
+func NewTracePlayer(trace []*Snapshot, speed ReplaySpeed) *TracePlayer {
+	return &TracePlayer{Speed: speed, trace: trace}
+}
+
+// Next waits until the next snapshot is due and returns a copy of it.
+// It returns io.EOF after the last snapshot, or ctx.Err() if ctx ends first.
+func (p *TracePlayer) Next(ctx context.Context) (*Snapshot, error) {
+	if len(p.trace) == 0 {
+		return nil, io.EOF
+	}
+	if p.pos == 0 && p.last.IsZero() && !p.StartAt.IsZero() {
+		p.offset = p.StartAt.Sub(p.trace[0].Timestamp)
+	}
+	if p.pos == len(p.trace) {
+		if !p.Loop {
+			return nil, io.EOF
+		}
+		// Continue one typical tick after the last snapshot.
+		p.offset += p.trace[len(p.trace)-1].Timestamp.Sub(p.trace[0].Timestamp) + p.typicalGap()
+		p.pos = 0
+	}
+
+	snap := p.trace[p.pos].clone()
+	snap.Timestamp = snap.Timestamp.Add(p.offset)
+
+	// The first snapshot is due immediately.
+	if !p.last.IsZero() {
+		if err := p.wait(ctx, snap.Timestamp.Sub(p.last)); err != nil {
+			return nil, err
+		}
+	}
+
+	p.pos++
+	p.last = snap.Timestamp
+	return snap, nil
+}
+
+func (p *TracePlayer) wait(ctx context.Context, gap time.Duration) error {
+	if p.Speed.Step {
+		select {
+		case <-p.Steps:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if p.Speed.Factor <= 0 || gap <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(time.Duration(float64(gap) / p.Speed.Factor))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// typicalGap is the first gap between snapshots, used between loop iterations.
+func (p *TracePlayer) typicalGap() time.Duration {
+	if len(p.trace) > 1 {
+		return p.trace[1].Timestamp.Sub(p.trace[0].Timestamp)
+	}
+	return time.Second
+}
+
+// clone copies s deeply: the filters and the state machine write to the
+// snapshots they get, and a looping trace hands out the same one again.
+func (s *Snapshot) clone() *Snapshot {
+	c := *s
+	c.PerCoreFreqMHz = copySlice(s.PerCoreFreqMHz)
+	c.PerCPULoad = copySlice(s.PerCPULoad)
+	c.HWThrottleReasons = copySlice(s.HWThrottleReasons)
+	c.ZoneThrottleReasons = copySlice(s.ZoneThrottleReasons)
+	c.Fans = copySlice(s.Fans)
+	c.DeviceTempC = copyMap(s.DeviceTempC)
+	c.TempSensors = copySlice(s.TempSensors)
+	c.ValidSignals = append([]string{}, s.ValidSignals...)
+	c.Sources = copyMap(s.Sources)
+	c.Rejected = copyMap(s.Rejected)
+	c.Raw = copyMap(s.Raw)
+	return &c
+}
+
+func copySlice[T any](src []T) []T {
+	if src == nil {
+		return nil
+	}
+	return append([]T{}, src...)
+}
+
+func copyMap[K comparable, V any](src map[K]V) map[K]V {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[K]V, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,118 @@
+package sensors
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// tickTrace is a trace of n snapshots one second apart.
+func tickTrace(n int) []*Snapshot {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	trace := make([]*Snapshot, n)
+	for i := range trace {
+		s := &Snapshot{Timestamp: start.Add(time.Duration(i) * time.Second), TempC: 60 + float64(i)}
+		s.SetSource(SignalTemp, ProvenanceMeasured, TraceBackend)
+		trace[i] = s
+	}
+	return trace
+}
+
+func TestTracePlayerPacing(t *testing.T) {
+	trace := tickTrace(3)
+	p := NewTracePlayer(trace, ReplaySpeed{Factor: 20}) // 1 s gaps play as 50 ms
+
+	start := time.Now()
+	for i := range trace {
+		s, err := p.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Timestamp.Equal(trace[i].Timestamp) {
+			t.Errorf("snapshot %d: timestamp %v, want the recorded %v", i, s.Timestamp, trace[i].Timestamp)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Errorf("three snapshots took %v, want about 100ms", elapsed)
+	}
+	if _, err := p.Next(context.Background()); err != io.EOF {
+		t.Errorf("after the last snapshot: %v, want io.EOF", err)
+	}
+}
+
+func TestTracePlayerStep(t *testing.T) {
+	steps := make(chan struct{})
+	p := NewTracePlayer(tickTrace(2), ReplaySpeed{Step: true})
+	p.Steps = steps
+
+	if _, err := p.Next(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// The second snapshot waits for a step, however short the gap.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.Next(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("without a step: %v, want deadline exceeded", err)
+	}
+	go func() { steps <- struct{}{} }()
+	if _, err := p.Next(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTracePlayerLoop(t *testing.T) {
+	trace := tickTrace(2)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewTracePlayer(trace, ReplaySpeed{Factor: 0})
+	p.Loop = true
+	p.StartAt = start
+
+	// Rebased on StartAt, and the second round continues one gap after the first.
+	for i := 0; i < 5; i++ {
+		s, err := p.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := start.Add(time.Duration(i) * time.Second); !s.Timestamp.Equal(want) {
+			t.Errorf("snapshot %d: timestamp %v, want %v", i, s.Timestamp, want)
+		}
+		if want := trace[i%2].TempC; s.TempC != want {
+			t.Errorf("snapshot %d: %.0f°C, want %.0f°C", i, s.TempC, want)
+		}
+	}
+}
+
+func TestTracePlayerCopiesSnapshots(t *testing.T) {
+	trace := tickTrace(1)
+	trace[0].DeviceTempC = map[string]float64{DeviceNVMe: 50}
+	trace[0].PerCoreFreqMHz = []int{3000, 3100}
+	trace[0].Raw = map[string]float64{SignalTemp: 60}
+	p := NewTracePlayer(trace, ReplaySpeed{Factor: 0})
+	p.Loop = true
+
+	s, err := p.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// What a filter or the state machine might do to the snapshot it gets.
+	s.ValidSignals[0] = "changed"
+	s.Reject(SignalTemp, "spike")
+	s.Raw[SignalTemp] = 99
+	s.DeviceTempC[DeviceNVMe] = 99
+	s.PerCoreFreqMHz[0] = 400
+
+	again, err := p.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Source(SignalTemp).Provenance != ProvenanceMeasured || len(again.Rejected) != 0 {
+		t.Errorf("rejection leaked into the trace: %v %v", again.Source(SignalTemp), again.Rejected)
+	}
+	if again.Raw[SignalTemp] != 60 || again.DeviceTempC[DeviceNVMe] != 50 || again.PerCoreFreqMHz[0] != 3000 {
+		t.Errorf("changes leaked into the trace: raw %v, devices %v, cores %v", again.Raw, again.DeviceTempC, again.PerCoreFreqMHz)
+	}
+	if again.ValidSignals[0] != SignalTemp {
+		t.Errorf("ValidSignals = %v, want [%s]", again.ValidSignals, SignalTemp)
+	}
+}
@@ -0,0 +1,89 @@
+package sensors
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Power sources reported in PowerSupplyData.Source.
+const (
+	PowerSourceAC      = "ac"
+	PowerSourceBattery = "battery"
+)
+
+// PowerSupplyData is what the machine runs on and the state of its battery.
+type PowerSupplyData struct {
+	Source         string  // PowerSourceAC or PowerSourceBattery
+	HasBattery     bool    // A system battery is present
+	BatteryPercent float64 // Charge left, 0-100
+	BatteryStatus  string  // As reported: Charging, Discharging, Full, Not charging, ...
+	BatteryTempC   float64 // 0 if the battery has no temperature sensor
+}
+
+// SysfsPowerSupply reads class/power_supply.
+// Root normally is DefaultSysfsRoot, but can point at a fake tree for testing.
+type SysfsPowerSupply struct {
+	Root string
+}
+
+// NewSysfsPowerSupply returns a reader rooted at DefaultSysfsRoot.
+func NewSysfsPowerSupply() *SysfsPowerSupply {
+	return &SysfsPowerSupply{Root: DefaultSysfsRoot}
+}
+
+// Read returns the power source and battery state. Machines without any
+// power supply entry (most desktops and VMs) return ErrUnsupported.
+func (p *SysfsPowerSupply) Read() (PowerSupplyData, error) {
+	dirs, err := filepath.Glob(filepath.Join(p.Root, "class", "power_supply", "*"))
+	if err != nil {
+		return PowerSupplyData{}, err
+	}
+
+	var data PowerSupplyData
+	adapters, online := 0, false
+	for _, dir := range dirs {
+		// Batteries of wireless mice and keyboards have scope "Device".
+		if strings.EqualFold(readSysfsString(filepath.Join(dir, "scope")), "Device") {
+			continue
+		}
+		switch readSysfsString(filepath.Join(dir, "type")) {
+		case "Mains", "USB":
+			// USB covers USB-C power delivery chargers.
+			adapters++
+			if readSysfsString(filepath.Join(dir, "online")) == "1" {
+				online = true
+			}
+		case "Battery":
+			if readSysfsString(filepath.Join(dir, "present")) == "0" || data.HasBattery {
+				continue
+			}
+			data.HasBattery = true
+			data.BatteryStatus = readSysfsString(filepath.Join(dir, "status"))
+			if capacity, err := readSysfsInt(filepath.Join(dir, "capacity")); err == nil {
+				data.BatteryPercent = float64(capacity)
+			}
+			// temp is in tenths of a degree Celsius.
+			if temp, err := readSysfsInt(filepath.Join(dir, "temp")); err == nil {
+				data.BatteryTempC = float64(temp) / 10.0
+			}
+		}
+	}
+
+	switch {
+	case online:
+		data.Source = PowerSourceAC
+	case !data.HasBattery && adapters == 0:
+		return PowerSupplyData{}, ErrUnsupported
+	case !data.HasBattery:
+		// Nothing else could be powering it.
+		data.Source = PowerSourceAC
+	case adapters > 0:
+		data.Source = PowerSourceBattery
+	case data.BatteryStatus == "Discharging":
+		// No adapter entry at all (some ARM boards): the battery status has to do.
+		data.Source = PowerSourceBattery
+	default:
+		data.Source = PowerSourceAC
+	}
+	return data, nil
+}
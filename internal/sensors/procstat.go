@@ -0,0 +1,187 @@
+package sensors
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProcRoot is where the Linux kernel exposes procfs.
+const DefaultProcRoot = "/proc"
+
+// LoadData holds CPU utilization over the last sampling interval, in percent.
+// Percent counts time spent running tasks (user, system, irq); iowait and
+// steal are reported separately so a starved VM does not look like real load.
+type LoadData struct {
+	Percent       float64
+	PerCPU        []float64
+	PerSocket     []float64 // Backends that report per package instead of per logical CPU
+	IOWaitPercent float64
+	StealPercent  float64
+	IRQPercent    float64 // hardirq + softirq
+}
+
+// cpuTimes holds the jiffy counters from one "cpu" line of /proc/stat.
+type cpuTimes struct {
+	user, nice, system, idle, iowait, irq, softirq, steal uint64
+}
+
+// cpuSample is the counters of one logical CPU, with N of its "cpuN" line.
+type cpuSample struct {
+	id    int
+	times cpuTimes
+}
+
+func (c cpuTimes) total() uint64 {
+	// guest/guest_nice are already included in user/nice, so they are not added.
+	return c.user + c.nice + c.system + c.idle + c.iowait + c.irq + c.softirq + c.steal
+}
+
+// ProcStatLoad computes utilization from the difference between two /proc/stat reads.
+// It keeps the previous counters between calls, so each Sample covers the time since
+// the last one. The very first Sample has nothing to compare with and waits
+// PrimeInterval for a second read.
+type ProcStatLoad struct {
+	Root          string
+	PrimeInterval time.Duration
+
+	mu      sync.Mutex
+	prev    cpuTimes
+	prevCPU map[int]cpuTimes // Keyed by CPU id
+	primed  bool
+}
+
+// NewProcStatLoad returns a sampler rooted at DefaultProcRoot.
+func NewProcStatLoad() *ProcStatLoad {
+	return &ProcStatLoad{
+		Root:          DefaultProcRoot,
+		PrimeInterval: 250 * time.Millisecond,
+	}
+}
+
+// Sample returns utilization since the previous call.
+func (p *ProcStatLoad) Sample(ctx context.Context) (LoadData, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.primed {
+		total, perCPU, err := readProcStat(filepath.Join(p.Root, "stat"))
+		if err != nil {
+			return LoadData{}, err
+		}
+		p.prev, p.prevCPU, p.primed = total, byCPU(perCPU), true
+
+		select {
+		case <-time.After(p.PrimeInterval):
+		case <-ctx.Done():
+			return LoadData{}, ctx.Err()
+		}
+	}
+
+	total, perCPU, err := readProcStat(filepath.Join(p.Root, "stat"))
+	if err != nil {
+		return LoadData{}, err
+	}
+
+	data := loadBetween(p.prev, total)
+	// CPUs can be hotplugged between reads; only compare the ones present in both,
+	// matched by id since an offline CPU drops out of the middle of the list.
+	for _, c := range perCPU {
+		if prev, ok := p.prevCPU[c.id]; ok {
+			data.PerCPU = append(data.PerCPU, loadBetween(prev, c.times).Percent)
+		}
+	}
+
+	p.prev, p.prevCPU = total, byCPU(perCPU)
+	return data, nil
+}
+
+// byCPU indexes per-CPU counters by CPU id.
+func byCPU(samples []cpuSample) map[int]cpuTimes {
+	m := make(map[int]cpuTimes, len(samples))
+	for _, c := range samples {
+		m[c.id] = c.times
+	}
+	return m
+}
+
+// loadBetween converts two counter readings into percentage shares.
+func loadBetween(prev, curr cpuTimes) LoadData {
+	delta := func(a, b uint64) float64 {
+		// Counters can go backwards when a CPU comes back online.
+		if b < a {
+			return 0
+		}
+		return float64(b - a)
+	}
+
+	total := delta(prev.total(), curr.total())
+	if total == 0 {
+		return LoadData{}
+	}
+
+	busy := delta(prev.user, curr.user) + delta(prev.nice, curr.nice) + delta(prev.system, curr.system)
+	irq := delta(prev.irq, curr.irq) + delta(prev.softirq, curr.softirq)
+
+	return LoadData{
+		Percent:       (busy + irq) / total * 100,
+		IOWaitPercent: delta(prev.iowait, curr.iowait) / total * 100,
+		StealPercent:  delta(prev.steal, curr.steal) / total * 100,
+		IRQPercent:    irq / total * 100,
+	}
+}
+
+// readProcStat parses the aggregate "cpu" line and every "cpuN" line.
+func readProcStat(path string) (cpuTimes, []cpuSample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return cpuTimes{}, nil, err
+	}
+	defer f.Close()
+
+	var total cpuTimes
+	var perCPU []cpuSample
+	found := false
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		var vals [8]uint64
+		for i := range vals {
+			v, err := strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				return cpuTimes{}, nil, fmt.Errorf("failed to parse %s: %v", path, err)
+			}
+			vals[i] = v
+		}
+		t := cpuTimes{
+			user: vals[0], nice: vals[1], system: vals[2], idle: vals[3],
+			iowait: vals[4], irq: vals[5], softirq: vals[6], steal: vals[7],
+		}
+
+		if fields[0] == "cpu" {
+			total = t
+			found = true
+		} else if id, err := strconv.Atoi(strings.TrimPrefix(fields[0], "cpu")); err == nil {
+			perCPU = append(perCPU, cpuSample{id, t})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return cpuTimes{}, nil, err
+	}
+	if !found {
+		return cpuTimes{}, nil, fmt.Errorf("no cpu line in %s", path)
+	}
+
+	return total, perCPU, nil
+}
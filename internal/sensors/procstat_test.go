@@ -0,0 +1,66 @@
+package sensors
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcStatLoadHotplug(t *testing.T) {
+	root := writeTree(t, map[string]string{"stat": `cpu  300 0 100 500 100 0 0 0 0 0
+cpu0 100 0 50 250 50 0 0 0 0 0
+cpu1 100 0 25 100 25 0 0 0 0 0
+cpu2 100 0 25 150 25 0 0 0 0 0`})
+	p := &ProcStatLoad{Root: root}
+	if _, err := p.Sample(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// cpu1 went offline: cpu2 is now second in the list and must still be
+	// compared with its own previous counters, not cpu1's.
+	next := `cpu  400 0 100 600 100 0 0 0 0 0
+cpu0 100 0 50 350 50 0 0 0 0 0
+cpu2 200 0 25 150 25 0 0 0 0 0`
+	if err := os.WriteFile(filepath.Join(root, "stat"), []byte(next), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{0, 100}
+	if len(got.PerCPU) != len(want) {
+		t.Fatalf("PerCPU = %v, want %v", got.PerCPU, want)
+	}
+	for i := range want {
+		if math.Abs(got.PerCPU[i]-want[i]) > 0.01 {
+			t.Errorf("PerCPU = %v, want %v", got.PerCPU, want)
+		}
+	}
+	if math.Abs(got.Percent-50) > 0.01 {
+		t.Errorf("Percent = %v, want 50", got.Percent)
+	}
+}
+
+func TestLoadBetween(t *testing.T) {
+	prev := cpuTimes{user: 100, system: 50, idle: 800, iowait: 0, irq: 0, softirq: 0, steal: 0}
+	curr := cpuTimes{user: 140, system: 60, idle: 830, iowait: 10, irq: 5, softirq: 5, steal: 20}
+	got := loadBetween(prev, curr)
+	// 120 jiffies: 50 busy + 10 irq = 50%, 10 iowait, 20 steal.
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"Percent", got.Percent, 50},
+		{"IOWaitPercent", got.IOWaitPercent, 100.0 / 12},
+		{"StealPercent", got.StealPercent, 100.0 / 6},
+		{"IRQPercent", got.IRQPercent, 100.0 / 12},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 0.01 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
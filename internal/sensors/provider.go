@@ -0,0 +1,95 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned by a Provider for signals its backend cannot read.
+var ErrUnsupported = errors.New("signal not supported by this backend")
+
+// Provider is a sensor backend. Each backend reads the core signals in its own
+// way (WMI queries, sysfs files, recorded data, ...) and CollectSnapshot combines
+// them. Signals a backend cannot read return ErrUnsupported. Reads must give up
+// when ctx is done; the collector may call them concurrently.
+type Provider interface {
+	// Name is the identifier used with --sensor-backend.
+	Name() string
+	// Available reports whether the backend can run on this machine. Probes
+	// that run a command are bounded by AvailableTimeout.
+	Available() bool
+
+	Temperature(ctx context.Context) (float64, error)
+	Frequency(ctx context.Context) (FrequencyData, error)
+	Load(ctx context.Context) (LoadData, error)
+}
+
+// AvailableTimeout bounds the command a backend may run to answer Available.
+// Auto-selection asks every backend in turn, so a hanging probe would stall
+// every tick.
+const AvailableTimeout = 3 * time.Second
+
+// PowerProvider is implemented by backends that can measure CPU power draw.
+type PowerProvider interface {
+	Power(ctx context.Context) (PowerData, error)
+}
+
+// ThrottleProvider is implemented by backends that can read OS throttle event
+// counters. Sample-style: values are increments since the previous call.
+type ThrottleProvider interface {
+	ThrottleCounters(ctx context.Context) (ThrottleData, error)
+}
+
+// MSRProvider is implemented by backends that can read the CPU's thermal status
+// registers directly.
+type MSRProvider interface {
+	ThermStatus(ctx context.Context) (MSRData, error)
+}
+
+// QuotaProvider is implemented by backends that can read CPU quota (CFS bandwidth)
+// throttling. Sample-style: values are increments since the previous call.
+type QuotaProvider interface {
+	CgroupThrottle(ctx context.Context) (CgroupData, error)
+}
+
+// PressureProvider is implemented by backends that can report CPU pressure stall
+// information, i.e. how long tasks waited for a CPU.
+type PressureProvider interface {
+	Pressure(ctx context.Context) (PressureData, error)
+}
+
+// ZoneProvider is implemented by backends that can report the passive cooling
+// limit of the ACPI thermal zones, i.e. how far the OS slows the CPU down for heat.
+type ZoneProvider interface {
+	ThermalZones(ctx context.Context) (ZoneData, error)
+}
+
+// FanProvider is implemented by backends that can read fan speeds.
+type FanProvider interface {
+	Fans(ctx context.Context) (FanData, error)
+}
+
+// PowerSupplyProvider is implemented by backends that can tell whether the
+// machine runs on AC or battery.
+type PowerSupplyProvider interface {
+	PowerSupply(ctx context.Context) (PowerSupplyData, error)
+}
+
+// DeviceTempProvider is implemented by backends that can read the temperature
+// of devices that throttle themselves: NVMe drives, SoC zones, batteries.
+type DeviceTempProvider interface {
+	DeviceTemps(ctx context.Context) (DeviceTempData, error)
+}
+
+// TempSensorProvider is implemented by backends that can list their individual
+// temperature sensors, so a TempMapping can choose among them.
+type TempSensorProvider interface {
+	TempSensors(ctx context.Context) ([]NamedTemp, error)
+}
+
+// simulator is implemented by backends whose data is synthetic (demo, simulation).
+// Everything such a backend produces is marked ProvenanceMocked.
+type simulator interface {
+	Simulated() bool
+}
@@ -0,0 +1,95 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PressureData holds CPU Pressure Stall Information: the share of wall time in
+// which tasks were runnable but had to wait for a CPU. "Some" means at least one
+// task was waiting, "full" that all non-idle tasks were (only meaningful inside
+// a cgroup; the system-wide line is reported as zero by recent kernels).
+type PressureData struct {
+	SomeAvg10   float64 // Percent, averaged over the last 10 s
+	SomeAvg60   float64
+	SomeTotalUs uint64 // Cumulative stall time since boot
+	FullAvg10   float64
+	FullAvg60   float64
+	FullTotalUs uint64
+	HasFull     bool // Kernels before 5.13 have no "full" line for cpu
+}
+
+// PSIReader reads /proc/pressure/cpu. The kernel keeps the running averages,
+// so unlike the load counters no previous sample is needed.
+type PSIReader struct {
+	ProcRoot string
+}
+
+// NewPSIReader returns a reader for the real /proc.
+func NewPSIReader() *PSIReader {
+	return &PSIReader{ProcRoot: DefaultProcRoot}
+}
+
+// CPU returns the current CPU pressure. A kernel without PSI (or booted with
+// psi=0) has no pressure file; that is reported as ErrUnsupported.
+func (r *PSIReader) CPU() (PressureData, error) {
+	path := filepath.Join(r.ProcRoot, "pressure", "cpu")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return PressureData{}, ErrUnsupported
+		}
+		return PressureData{}, err
+	}
+	return parsePressure(string(data))
+}
+
+// parsePressure parses lines like
+// "some avg10=0.82 avg60=0.86 avg300=1.19 total=23710402".
+func parsePressure(text string) (PressureData, error) {
+	var p PressureData
+	seenSome := false
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var avg10, avg60 *float64
+		var total *uint64
+		switch fields[0] {
+		case "some":
+			avg10, avg60, total = &p.SomeAvg10, &p.SomeAvg60, &p.SomeTotalUs
+			seenSome = true
+		case "full":
+			avg10, avg60, total = &p.FullAvg10, &p.FullAvg60, &p.FullTotalUs
+			p.HasFull = true
+		default:
+			continue
+		}
+		for _, kv := range fields[1:] {
+			key, value, ok := strings.Cut(kv, "=")
+			if !ok {
+				continue
+			}
+			var err error
+			switch key {
+			case "avg10":
+				*avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				*avg60, err = strconv.ParseFloat(value, 64)
+			case "total":
+				*total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return PressureData{}, fmt.Errorf("invalid pressure field %q: %v", kv, err)
+			}
+		}
+	}
+	if !seenSome {
+		return PressureData{}, fmt.Errorf("no \"some\" line in pressure data")
+	}
+	return p, nil
+}
@@ -0,0 +1,49 @@
+package sensors
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPSIReader(t *testing.T) {
+	root := writeTree(t, map[string]string{"pressure/cpu": `some avg10=12.50 avg60=8.25 avg300=3.00 total=23710402
+full avg10=1.50 avg60=0.75 avg300=0.10 total=1204000`})
+	got, err := (&PSIReader{ProcRoot: root}).CPU()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PressureData{
+		SomeAvg10: 12.5, SomeAvg60: 8.25, SomeTotalUs: 23710402,
+		FullAvg10: 1.5, FullAvg60: 0.75, FullTotalUs: 1204000,
+		HasFull: true,
+	}
+	if got != want {
+		t.Errorf("CPU() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPSIReaderWithoutFull(t *testing.T) {
+	// Kernels before 5.13 only have the "some" line for cpu.
+	root := writeTree(t, map[string]string{"pressure/cpu": "some avg10=0.82 avg60=0.86 avg300=1.19 total=23710402"})
+	got, err := (&PSIReader{ProcRoot: root}).CPU()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.HasFull || got.SomeAvg10 != 0.82 {
+		t.Errorf("CPU() = %+v, want some only", got)
+	}
+}
+
+func TestPSIReaderErrors(t *testing.T) {
+	if _, err := (&PSIReader{ProcRoot: filepath.Join(t.TempDir(), "proc")}).CPU(); err != ErrUnsupported {
+		t.Errorf("without a pressure file: %v, want ErrUnsupported", err)
+	}
+	for _, text := range []string{
+		"full avg10=1.00 avg60=1.00 avg300=1.00 total=10",
+		"some avg10=high avg60=1.00 avg300=1.00 total=10",
+	} {
+		if _, err := parsePressure(text); err == nil {
+			t.Errorf("parsePressure(%q) succeeded", text)
+		}
+	}
+}
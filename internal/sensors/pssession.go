@@ -0,0 +1,256 @@
+package sensors
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os/exec"
+	"unicode/utf16"
+)
+
+// psWorkerScript runs inside the long-lived PowerShell process. It reads one JSON
+// request per line from stdin, runs the command and writes one JSON response per
+// line to stdout. Anything else the command prints is captured, never written raw.
+const psWorkerScript = `
+$ErrorActionPreference = 'Stop'
+$ProgressPreference = 'SilentlyContinue'
+[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+while ($true) {
+	$line = [Console]::In.ReadLine()
+	if ($null -eq $line) { break }
+	if ($line.Trim() -eq '') { continue }
+	$req = $line | ConvertFrom-Json
+	$resp = [ordered]@{ id = $req.id; output = ''; error = '' }
+	try {
+		$resp.output = (Invoke-Expression $req.command | Out-String).Trim()
+	} catch {
+		$resp.error = $_.Exception.Message
+	}
+	[Console]::Out.WriteLine(($resp | ConvertTo-Json -Compress))
+	[Console]::Out.Flush()
+}
+`
+
+// psRequest and psResponse are the newline-delimited JSON frames exchanged with the worker.
+type psRequest struct {
+	ID      int    `json:"id"`
+	Command string `json:"command"`
+}
+
+type psResponse struct {
+	ID     int    `json:"id"`
+	Output string `json:"output"`
+	Error  string `json:"error"`
+}
+
+// DefaultPSWorkers is how many PowerShell processes a session runs at most.
+// The collector queries the providers of one tick in parallel; with a single
+// worker their queries would queue behind each other.
+const DefaultPSWorkers = 3
+
+// PSSession keeps a few PowerShell processes alive and sends them commands over
+// stdin, so each sensor read costs a pipe round trip instead of a process start.
+// Each worker runs one request at a time; workers are started on demand, up to
+// the session's size. A worker that exits is restarted on the next request;
+// a worker that does not answer before the request's deadline is killed and
+// restarted the same way. If a fresh worker dies too (e.g. a locked-down host
+// refuses the worker script), the request falls back to Fallback.
+type PSSession struct {
+	// Args is the worker's command line. It defaults to powershell running
+	// psWorkerScript, but any program speaking the same framing can stand in.
+	Args     []string
+	Fallback Executor
+
+	slots chan *psSlot // Idle slots; a request holds one, and acquiring it honors ctx
+	size  int
+}
+
+// psSlot is one place in the pool: a worker, once started, and its request ids.
+type psSlot struct {
+	nextID int
+	worker *psWorker
+}
+
+// psWorker is one running worker process.
+type psWorker struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan psResponse // Closed when stdout ends, i.e. the process died
+	done      chan struct{}   // Closed by stop so the reader never blocks on an abandoned worker
+}
+
+// NewPSSession returns a session of DefaultPSWorkers workers, each starting
+// powershell on first use.
+func NewPSSession() *PSSession {
+	return newPSSession(DefaultPSWorkers)
+}
+
+func newPSSession(workers int) *PSSession {
+	s := &PSSession{
+		Args:     []string{"powershell", "-NoProfile", "-NonInteractive", "-EncodedCommand", encodePSCommand(psWorkerScript)},
+		Fallback: NewPowerShellExecutor(),
+		slots:    make(chan *psSlot, workers),
+		size:     workers,
+	}
+	for i := 0; i < workers; i++ {
+		s.slots <- &psSlot{}
+	}
+	return s
+}
+
+// Run sends one command to an idle worker and waits for its output.
+func (s *PSSession) Run(ctx context.Context, command string) (string, error) {
+	var slot *psSlot
+	select {
+	case slot = <-s.slots:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { s.slots <- slot }()
+
+	// A worker that crashed since the last request is only noticed on write or
+	// read, so a dead worker gets one transparent retry with a fresh process.
+	resp, err := slot.roundTrip(ctx, s.Args, command)
+	if err == errWorkerExited && ctx.Err() == nil {
+		resp, err = slot.roundTrip(ctx, s.Args, command)
+	}
+	if err == errWorkerExited && ctx.Err() == nil && s.Fallback != nil {
+		return s.Fallback.Run(ctx, command)
+	}
+	if err != nil {
+		return "", err
+	}
+	if resp.Error != "" {
+		return "", &ExecError{Kind: classifyStderr(resp.Error), Command: command, Stderr: resp.Error}
+	}
+	return resp.Output, nil
+}
+
+// Close stops the running workers, waiting for requests in flight.
+func (s *PSSession) Close() error {
+	slots := make([]*psSlot, 0, s.size)
+	for i := 0; i < s.size; i++ {
+		slot := <-s.slots
+		slot.stop()
+		slots = append(slots, slot)
+	}
+	for _, slot := range slots {
+		s.slots <- slot
+	}
+	return nil
+}
+
+var errWorkerExited = errors.New("powershell worker exited")
+
+func (s *psSlot) roundTrip(ctx context.Context, args []string, command string) (psResponse, error) {
+	if s.worker == nil {
+		w, err := startPSWorker(args)
+		if err != nil {
+			execErr := &ExecError{Command: command, Err: err}
+			if errors.Is(err, exec.ErrNotFound) {
+				execErr.Kind = ErrNotFound
+			}
+			return psResponse{}, execErr
+		}
+		s.worker = w
+	}
+
+	s.nextID++
+	frame, err := json.Marshal(psRequest{ID: s.nextID, Command: command})
+	if err != nil {
+		return psResponse{}, err
+	}
+	if _, err := s.worker.stdin.Write(append(frame, '\n')); err != nil {
+		s.stop()
+		return psResponse{}, errWorkerExited
+	}
+
+	for {
+		select {
+		case resp, ok := <-s.worker.responses:
+			if !ok {
+				s.stop()
+				return psResponse{}, errWorkerExited
+			}
+			if resp.ID != s.nextID {
+				// Leftover answer to an earlier request; ignore it.
+				continue
+			}
+			return resp, nil
+		case <-ctx.Done():
+			// The worker is hung or too slow; its answer would arrive out of
+			// order, so replace it rather than wait.
+			s.stop()
+			return psResponse{}, &ExecError{Kind: ErrTimeout, Command: command, Err: ctx.Err()}
+		}
+	}
+}
+
+func (s *psSlot) stop() {
+	if s.worker == nil {
+		return
+	}
+	close(s.worker.done)
+	s.worker.stdin.Close()
+	if s.worker.cmd.Process != nil {
+		_ = s.worker.cmd.Process.Kill()
+	}
+	s.worker = nil
+}
+
+func startPSWorker(args []string) (*psWorker, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	w := &psWorker{
+		cmd:       cmd,
+		stdin:     stdin,
+		responses: make(chan psResponse, 1),
+		done:      make(chan struct{}),
+	}
+
+	go func() {
+		defer close(w.responses)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			var resp psResponse
+			if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+				// Not a frame (e.g. a stray host message); skip it.
+				continue
+			}
+			select {
+			case w.responses <- resp:
+			case <-w.done:
+			}
+		}
+		_ = cmd.Wait()
+	}()
+
+	return w, nil
+}
+
+// encodePSCommand encodes a script for -EncodedCommand (base64 of UTF-16LE),
+// which keeps stdin free for the request stream.
+func encodePSCommand(script string) string {
+	units := utf16.Encode([]rune(script))
+	buf := make([]byte, len(units)*2)
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(buf[i*2:], u)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
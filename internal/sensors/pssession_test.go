@@ -0,0 +1,180 @@
+package sensors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWorkerEnv makes the test binary act as a PowerShell worker: see
+// TestHelperPSWorker.
+const fakeWorkerEnv = "TTA_FAKE_PS_WORKER"
+
+// TestHelperPSWorker is not a test: run as a child process with fakeWorkerEnv
+// set, it speaks the worker framing. Commands:
+//
+//	echo TEXT   answers TEXT, after a line that is not a frame
+//	fail TEXT   answers with error TEXT
+//	pid         answers the process id
+//	hang        never answers
+//	die         exits without answering
+//	bye TEXT    answers TEXT, then exits
+//	stale TEXT  answers once with the previous id, then TEXT
+func TestHelperPSWorker(t *testing.T) {
+	if os.Getenv(fakeWorkerEnv) != "1" {
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req psRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			continue
+		}
+		verb, arg, _ := strings.Cut(req.Command, " ")
+		resp := psResponse{ID: req.ID}
+		switch verb {
+		case "echo":
+			fmt.Println("WARNING: not a frame")
+			resp.Output = arg
+		case "fail":
+			resp.Error = arg
+		case "pid":
+			resp.Output = strconv.Itoa(os.Getpid())
+		case "hang":
+			time.Sleep(time.Hour)
+		case "die":
+			os.Exit(1)
+		case "bye":
+			resp.Output = arg
+		case "stale":
+			stale, _ := json.Marshal(psResponse{ID: req.ID - 1, Output: "stale"})
+			fmt.Println(string(stale))
+			resp.Output = arg
+		}
+		frame, _ := json.Marshal(resp)
+		fmt.Println(string(frame))
+		if verb == "bye" {
+			os.Exit(0)
+		}
+	}
+	os.Exit(0)
+}
+
+// fallbackExecutor records that the session gave up on its workers.
+type fallbackExecutor struct{ calls int }
+
+func (f *fallbackExecutor) Run(ctx context.Context, command string) (string, error) {
+	f.calls++
+	return "fallback", nil
+}
+
+func newFakeSession(t *testing.T, workers int) (*PSSession, *fallbackExecutor) {
+	t.Helper()
+	t.Setenv(fakeWorkerEnv, "1")
+	fallback := &fallbackExecutor{}
+	s := newPSSession(workers)
+	s.Args = []string{os.Args[0], "-test.run=^TestHelperPSWorker$"}
+	s.Fallback = fallback
+	t.Cleanup(func() { s.Close() })
+	return s, fallback
+}
+
+func run(t *testing.T, s *PSSession, command string) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := s.Run(ctx, command)
+	if err != nil {
+		t.Fatalf("Run(%q): %v", command, err)
+	}
+	return out
+}
+
+func TestPSSessionFraming(t *testing.T) {
+	s, _ := newFakeSession(t, 1)
+	if got := run(t, s, "echo hello world"); got != "hello world" {
+		t.Errorf("echo = %q, want %q", got, "hello world")
+	}
+	// Requests reuse the worker.
+	first := run(t, s, "pid")
+	if got := run(t, s, "pid"); got != first {
+		t.Errorf("worker restarted between requests: pid %s, then %s", first, got)
+	}
+	// An answer to an earlier request is skipped.
+	if got := run(t, s, "stale fresh"); got != "fresh" {
+		t.Errorf("stale = %q, want %q", got, "fresh")
+	}
+}
+
+func TestPSSessionCommandError(t *testing.T) {
+	s, _ := newFakeSession(t, 1)
+	_, err := s.Run(context.Background(), "fail Invalid class \"Win32_Nope\"")
+	var execErr *ExecError
+	if !errors.As(err, &execErr) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("error = %v, want an ExecError of kind ErrNotFound", err)
+	}
+	// The worker survives a failed command.
+	run(t, s, "echo still here")
+}
+
+func TestPSSessionTimeoutRestartsWorker(t *testing.T) {
+	s, _ := newFakeSession(t, 1)
+	before := run(t, s, "pid")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := s.Run(ctx, "hang"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("hang: error = %v, want ErrTimeout", err)
+	}
+
+	if after := run(t, s, "pid"); after == before {
+		t.Errorf("hung worker %s was not replaced", before)
+	}
+}
+
+func TestPSSessionRestartsExitedWorker(t *testing.T) {
+	s, fallback := newFakeSession(t, 1)
+	before := run(t, s, "pid")
+	run(t, s, "bye done")
+	if after := run(t, s, "pid"); after == before {
+		t.Errorf("exited worker %s was not replaced", before)
+	}
+	if fallback.calls != 0 {
+		t.Errorf("fallback used %d time(s) for a worker that merely exited", fallback.calls)
+	}
+}
+
+func TestPSSessionFallback(t *testing.T) {
+	s, fallback := newFakeSession(t, 1)
+	// The fresh worker dies as well, so the request goes to the fallback.
+	if got := run(t, s, "die"); got != "fallback" || fallback.calls != 1 {
+		t.Errorf("die = %q with %d fallback call(s), want the fallback's answer", got, fallback.calls)
+	}
+}
+
+func TestPSSessionRunsWorkersInParallel(t *testing.T) {
+	s, _ := newFakeSession(t, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	// One worker hangs; the other must still answer.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = s.Run(ctx, "hang")
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if got, err := s.Run(ctx, "echo free"); err != nil || got != "free" {
+		t.Errorf("echo next to a hung worker = %q, %v", got, err)
+	}
+	wg.Wait()
+}
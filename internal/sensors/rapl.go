@@ -0,0 +1,152 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PowerData holds average power draw over the last sampling interval, in watts.
+// Domains a machine does not expose stay 0.
+type PowerData struct {
+	PackageW float64 // Sum over all packages (sockets)
+	CoreW    float64
+	UncoreW  float64 // Integrated GPU and other uncore parts on client chips
+	DRAMW    float64
+}
+
+// raplSample is one energy counter reading.
+type raplSample struct {
+	energyUJ uint64
+	at       time.Time
+}
+
+// RAPLPower computes power from the powercap energy counters
+// (class/powercap/intel-rapl:*/energy_uj). Like ProcStatLoad, it keeps the previous
+// reading between calls; the first call waits PrimeInterval for a second one.
+// AMD Zen exposes the same intel-rapl powercap interface.
+type RAPLPower struct {
+	Root          string
+	PrimeInterval time.Duration
+
+	mu   sync.Mutex
+	prev map[string]raplSample
+}
+
+// NewRAPLPower returns a reader rooted at DefaultSysfsRoot.
+func NewRAPLPower() *RAPLPower {
+	return &RAPLPower{
+		Root:          DefaultSysfsRoot,
+		PrimeInterval: 250 * time.Millisecond,
+	}
+}
+
+// raplDomain is one powercap zone, e.g. intel-rapl:0 (package-0) or intel-rapl:0:0 (core).
+type raplDomain struct {
+	dir  string
+	name string
+}
+
+func (r *RAPLPower) domains() ([]raplDomain, error) {
+	dirs, err := filepath.Glob(filepath.Join(r.Root, "class", "powercap", "intel-rapl:*"))
+	if err != nil {
+		return nil, err
+	}
+	var domains []raplDomain
+	for _, dir := range dirs {
+		name := readSysfsString(filepath.Join(dir, "name"))
+		if name == "" {
+			continue
+		}
+		domains = append(domains, raplDomain{dir: dir, name: name})
+	}
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("no RAPL domains found under %s", r.Root)
+	}
+	return domains, nil
+}
+
+// Power returns average watts per domain since the previous call.
+func (r *RAPLPower) Power(ctx context.Context) (PowerData, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	domains, err := r.domains()
+	if err != nil {
+		return PowerData{}, err
+	}
+
+	if r.prev == nil {
+		prev, err := readRAPL(domains)
+		if err != nil {
+			return PowerData{}, err
+		}
+		r.prev = prev
+
+		select {
+		case <-time.After(r.PrimeInterval):
+		case <-ctx.Done():
+			return PowerData{}, ctx.Err()
+		}
+	}
+
+	curr, err := readRAPL(domains)
+	if err != nil {
+		return PowerData{}, err
+	}
+
+	var data PowerData
+	for _, d := range domains {
+		before, ok := r.prev[d.dir]
+		if !ok {
+			continue
+		}
+		after := curr[d.dir]
+		seconds := after.at.Sub(before.at).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+
+		delta := after.energyUJ - before.energyUJ
+		if after.energyUJ < before.energyUJ {
+			// The counter wrapped around at max_energy_range_uj.
+			maxRange, err := readSysfsInt(filepath.Join(d.dir, "max_energy_range_uj"))
+			if err != nil || uint64(maxRange) < before.energyUJ {
+				continue
+			}
+			delta = uint64(maxRange) - before.energyUJ + after.energyUJ
+		}
+		watts := float64(delta) / 1e6 / seconds
+
+		switch {
+		case strings.HasPrefix(d.name, "package"):
+			data.PackageW += watts
+		case d.name == "core":
+			data.CoreW += watts
+		case d.name == "uncore":
+			data.UncoreW += watts
+		case d.name == "dram":
+			data.DRAMW += watts
+		}
+	}
+
+	r.prev = curr
+	return data, nil
+}
+
+// readRAPL reads energy_uj of every domain. energy_uj is root-only on kernels
+// patched for CVE-2020-8694, so a permission error is common without elevation.
+func readRAPL(domains []raplDomain) (map[string]raplSample, error) {
+	samples := make(map[string]raplSample, len(domains))
+	for _, d := range domains {
+		energy, err := readSysfsInt(filepath.Join(d.dir, "energy_uj"))
+		if err != nil {
+			return nil, err
+		}
+		samples[d.dir] = raplSample{energyUJ: uint64(energy), at: time.Now()}
+	}
+	return samples, nil
+}
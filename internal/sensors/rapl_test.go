@@ -0,0 +1,84 @@
+package sensors
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// raplTree has a package domain and its core subdomain.
+func raplTree(t *testing.T, pkgUJ, coreUJ string) string {
+	return writeTree(t, map[string]string{
+		"class/powercap/intel-rapl:0/name":                  "package-0",
+		"class/powercap/intel-rapl:0/energy_uj":             pkgUJ,
+		"class/powercap/intel-rapl:0/max_energy_range_uj":   "262143328850",
+		"class/powercap/intel-rapl:0:0/name":                "core",
+		"class/powercap/intel-rapl:0:0/energy_uj":           coreUJ,
+		"class/powercap/intel-rapl:0:0/max_energy_range_uj": "262143328850",
+	})
+}
+
+// setEnergy overwrites a domain's energy counter.
+func setEnergy(t *testing.T, root, domain, uj string) {
+	t.Helper()
+	path := filepath.Join(root, "class/powercap", domain, "energy_uj")
+	if err := os.WriteFile(path, []byte(uj+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// backdate pretends the previous reading was taken one second ago.
+func backdate(r *RAPLPower) {
+	for dir, s := range r.prev {
+		s.at = time.Now().Add(-time.Second)
+		r.prev[dir] = s
+	}
+}
+
+func TestRAPLPower(t *testing.T) {
+	root := raplTree(t, "1000000000", "400000000")
+	r := &RAPLPower{Root: root}
+	if _, err := r.Power(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	backdate(r)
+
+	setEnergy(t, root, "intel-rapl:0", "1025000000")  // 25 J
+	setEnergy(t, root, "intel-rapl:0:0", "418000000") // 18 J
+	got, err := r.Power(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got.PackageW-25) > 1 || math.Abs(got.CoreW-18) > 1 {
+		t.Errorf("Power() = %.1f W package, %.1f W core, want about 25 and 18", got.PackageW, got.CoreW)
+	}
+}
+
+func TestRAPLPowerWraparound(t *testing.T) {
+	// 3 J before the counter wraps at max_energy_range_uj, 7 J after.
+	root := raplTree(t, "262140328850", "0")
+	r := &RAPLPower{Root: root}
+	if _, err := r.Power(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	backdate(r)
+
+	setEnergy(t, root, "intel-rapl:0", "7000000")
+	got, err := r.Power(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got.PackageW-10) > 0.5 {
+		t.Errorf("package power across a wrap = %.1f W, want about 10", got.PackageW)
+	}
+}
+
+func TestRAPLPowerNoDomains(t *testing.T) {
+	root := writeTree(t, map[string]string{"class/powercap/intel-rapl:0/energy_uj": "1"})
+	if _, err := (&RAPLPower{Root: root}).Power(context.Background()); err == nil {
+		t.Error("no error for a domain without a name")
+	}
+}
@@ -0,0 +1,134 @@
+package sensors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BackendAuto selects the first available registered backend.
+const BackendAuto = "auto"
+
+var (
+	registryMu sync.Mutex
+	providers  []Provider
+	forced     = BackendAuto
+)
+
+func init() {
+	// Registration order is the auto-selection preference order.
+	Register(NewLinuxProvider())
+	Register(NewHardwareMonitorProvider())
+	Register(NewPerfCounterProvider())
+	Register(NewWMIProvider())
+	Register(NewMockProvider())
+}
+
+// Register adds a backend to the registry. Backends registered earlier are
+// preferred during auto-selection. Registering a name twice replaces the old entry.
+func Register(p Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i, existing := range providers {
+		if existing.Name() == p.Name() {
+			providers[i] = p
+			return
+		}
+	}
+	providers = append(providers, p)
+}
+
+// Backends returns the names of all registered backends, sorted.
+func Backends() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := make([]string, 0, len(providers))
+	for _, p := range providers {
+		names = append(names, p.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetBackend forces a backend by name, or restores auto-selection with BackendAuto.
+func SetBackend(name string) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || name == BackendAuto {
+		forced = BackendAuto
+		return nil
+	}
+	if lookupLocked(name) == nil {
+		return fmt.Errorf("unknown sensor backend %q", name)
+	}
+	forced = name
+	return nil
+}
+
+// ActiveProvider returns the backend CollectSnapshot should use.
+// A forced backend is returned even if it reports itself unavailable, so it
+// can be exercised against fake data; auto-selection only considers available ones.
+func ActiveProvider() (Provider, error) {
+	if p := forcedProvider(); p != nil {
+		return p, nil
+	}
+	for _, p := range registered() {
+		if p.Available() {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no sensor backend available on this system")
+}
+
+// forcedProvider returns the backend set with SetBackend, or nil when auto-selecting.
+func forcedProvider() Provider {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if forced == BackendAuto {
+		return nil
+	}
+	return lookupLocked(forced)
+}
+
+// registered returns a copy of the backends in preference order. Availability
+// probes may run commands, so callers ask them without holding registryMu.
+func registered() []Provider {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]Provider(nil), providers...)
+}
+
+// Lookup returns the registered backend with the given name, or nil.
+// Commands use it to configure a specific backend (e.g. the Linux cgroup path).
+func Lookup(name string) Provider {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return lookupLocked(name)
+}
+
+func lookupLocked(name string) Provider {
+	for _, p := range providers {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// candidateProviders returns the forced backend, or every available one in
+// preference order when auto-selecting.
+func candidateProviders() []Provider {
+	if p := forcedProvider(); p != nil {
+		return []Provider{p}
+	}
+	var list []Provider
+	for _, p := range registered() {
+		if p.Available() {
+			list = append(list, p)
+		}
+	}
+	return list
+}
@@ -1,14 +1,207 @@
 package sensors
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// Signal names used in ValidSignals and Sources.
+const (
+	SignalTemp        = "TempC"
+	SignalFreq        = "FreqMHz"
+	SignalBaseFreq    = "BaseFreqMHz"
+	SignalLoad        = "LoadPercent"
+	SignalPower       = "PowerW"
+	SignalThrottle    = "ThrottleEvents"
+	SignalThermStatus = "ThermStatus"
+	SignalQuota       = "CgroupThrottle"
+	SignalPressure    = "CPUPressure"
+	SignalZones       = "ThermalZones"
+	SignalFans        = "Fans"
+	SignalPowerSource = "PowerSource"
+	SignalDeviceTemps = "DeviceTemps"
+)
+
+// CoreSignals are the signals every backend provides and confidence is judged on.
+var CoreSignals = []string{SignalTemp, SignalFreq, SignalBaseFreq, SignalLoad}
+
+// Provenance describes how a signal's value was obtained.
+type Provenance string
+
+const (
+	ProvenanceMeasured  Provenance = "measured"  // Read directly from a sensor
+	ProvenanceEstimated Provenance = "estimated" // Derived from a related reading; only recorded traces use it
+	ProvenanceStale     Provenance = "stale"     // Last good value; the current read missed its deadline
+	ProvenanceMocked    Provenance = "mocked"    // Synthetic data from a demo/simulation source
+	ProvenanceMissing   Provenance = "missing"   // Backend could not provide it
+	ProvenanceRejected  Provenance = "rejected"  // Read, but failed a sanity check; see Snapshot.Rejected
+)
+
+// HasValue reports whether a signal with this provenance can be used for analysis.
+func (p Provenance) HasValue() bool {
+	return p != ProvenanceMissing && p != ProvenanceRejected
+}
+
+// SignalSource records the provenance of one signal and the backend that produced it.
+type SignalSource struct {
+	Provenance Provenance
+	Backend    string
+}
+
+func (src SignalSource) String() string {
+	if src.Backend == "" {
+		return string(src.Provenance)
+	}
+	return fmt.Sprintf("%s/%s", src.Provenance, src.Backend)
+}
 
 // Snapshot represents a point-in-time capture of system thermal state.
 // All sensors feed into this struct.
 type Snapshot struct {
-	TempC        float64
-	FreqMHz      int
-	BaseFreqMHz  int
-	LoadPercent  float64
+	TempC          float64
+	FreqMHz        int // Average across cores
+	FreqMinMHz     int // Slowest core
+	FreqMaxMHz     int // Fastest core
+	MaxFreqMHz     int // Rated maximum (turbo) clock, 0 if unknown
+	PerCoreFreqMHz []int
+	BaseFreqMHz    int
+	LoadPercent    float64
+	PerCPULoad     []float64
+	IOWaitPercent  float64
+	StealPercent   float64 // Time a hypervisor ran something else while we wanted the CPU
+	IRQPercent     float64
+	PowerW         float64 // CPU package power, summed over sockets
+	PowerCoreW     float64
+	PowerUncoreW   float64
+	PowerDRAMW     float64 // Memory power, not included in PowerW
+
+	// Kernel thermal throttle counters, as increments during this tick.
+	CoreThrottleEvents    uint64
+	PackageThrottleEvents uint64
+	CoreThrottleMs        uint64
+	PackageThrottleMs     uint64
+
+	// Decoded IA32_(PACKAGE_)THERM_STATUS MSRs.
+	HWThrottling      bool     // Thermal status or PROCHOT active, or logged since the last read when logs are cleared
+	HWThrottleReasons []string // Limits, e.g. "PROCHOT asserted", "logged since boot or last clear (power limit)"
+	TjMaxC            int
+	MSRTempC          float64 // Package digital readout (TjMax minus distance to it), 0 if unknown
+
+	// CPU quota (CFS bandwidth) throttling of the cgroup, as increments during this tick.
+	// This is a scheduler limit, not a thermal one.
+	CgroupPath             string
+	CgroupPeriods          uint64
+	CgroupThrottledPeriods uint64
+	CgroupThrottledMs      float64
+
+	// CPU pressure stall information: percent of time tasks waited for a CPU.
+	// "Some" is at least one task waiting, "full" all of them.
+	PSISomeAvg10   float64
+	PSISomeAvg60   float64
+	PSISomeTotalUs uint64 // Cumulative since boot
+	PSIFullAvg10   float64
+	PSIFullAvg60   float64
+	PSIFullTotalUs uint64
+
+	// ACPI thermal zones: the tightest passive cooling limit, in percent of full
+	// speed (100 is unrestricted), and a description of every limiting zone.
+	ZonePassiveLimit    float64
+	ZoneThrottleReasons []string
+
+	Fans []Fan // Every fan with a tachometer
+
+	// What the machine runs on. Firmware often switches to a lower power
+	// profile on battery, which shows up as more throttling.
+	PowerSource    string // PowerSourceAC or PowerSourceBattery
+	HasBattery     bool
+	BatteryPercent float64
+	BatteryTempC   float64 // 0 if the battery has no temperature sensor
+
+	// Temperature of each device zone besides the CPU, keyed by DeviceNVMe,
+	// DeviceSoC or DeviceBattery. Only zones the machine has are present.
+	DeviceTempC map[string]float64
+
+	// Sensors TempC was taken from when a TempMapping chose them, e.g.
+	// "hwmon:k10temp/Tccd1"; empty when the backend picked the sensor itself.
+	TempSensors []string
+
 	Timestamp    time.Time
-	ValidSignals []string // List of signals that were successfully collected
+	ValidSignals []string                // List of signals that hold a usable value (not missing or rejected)
+	Sources      map[string]SignalSource // Provenance of every signal, including missing ones
+	Rejected     map[string]string       // Why each rejected signal failed its sanity check
+	Raw          map[string]float64      // Unfiltered value of each smoothed signal
+}
+
+// HasSignal reports whether the named signal holds a value.
+func (s *Snapshot) HasSignal(name string) bool {
+	for _, v := range s.ValidSignals {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Source returns the provenance of a signal; unknown signals are missing.
+func (s *Snapshot) Source(name string) SignalSource {
+	if src, ok := s.Sources[name]; ok {
+		return src
+	}
+	return SignalSource{Provenance: ProvenanceMissing}
+}
+
+// ThrottleEvents is the number of core and package throttle events during this tick.
+func (s *Snapshot) ThrottleEvents() uint64 {
+	return s.CoreThrottleEvents + s.PackageThrottleEvents
+}
+
+// ThrottleMs is the longest throttled time reported by either counter during this tick.
+// Core and package time overlap, so they are not added.
+func (s *Snapshot) ThrottleMs() uint64 {
+	if s.CoreThrottleMs > s.PackageThrottleMs {
+		return s.CoreThrottleMs
+	}
+	return s.PackageThrottleMs
+}
+
+// SourceSummary returns every signal's provenance as "provenance/backend" strings,
+// suitable for logging.
+func (s *Snapshot) SourceSummary() map[string]string {
+	summary := make(map[string]string, len(s.Sources))
+	for name, src := range s.Sources {
+		summary[name] = src.String()
+	}
+	return summary
+}
+
+// Reject marks a signal as failing a sanity check. Its value stays on the
+// snapshot for the record, but it no longer counts as a valid signal.
+func (s *Snapshot) Reject(name, reason string) {
+	if s.Rejected == nil {
+		s.Rejected = make(map[string]string)
+	}
+	s.Rejected[name] = reason
+	s.SetSource(name, ProvenanceRejected, s.Source(name).Backend)
+}
+
+// SetSource records a signal's provenance and keeps ValidSignals in sync:
+// missing and rejected signals are removed from it, everything else is added.
+func (s *Snapshot) SetSource(name string, p Provenance, backend string) {
+	if s.Sources == nil {
+		s.Sources = make(map[string]SignalSource)
+	}
+	s.Sources[name] = SignalSource{Provenance: p, Backend: backend}
+
+	if p.HasValue() {
+		if !s.HasSignal(name) {
+			s.ValidSignals = append(s.ValidSignals, name)
+		}
+		return
+	}
+	for i, v := range s.ValidSignals {
+		if v == name {
+			s.ValidSignals = append(s.ValidSignals[:i], s.ValidSignals[i+1:]...)
+			break
+		}
+	}
 }
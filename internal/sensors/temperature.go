@@ -1,34 +1,35 @@
 package sensors
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
-	"strconv"
 )
 
-// GetCPUTemperature fetches the current CPU temperature in Celsius.
+// Temperature fetches the current CPU temperature in Celsius.
 // It attempts to read from MSAcpi_ThermalZoneTemperature.
 // Returns 0 and an error if unavailable.
-func GetCPUTemperature() (float64, error) {
+func (p *WMIProvider) Temperature(ctx context.Context) (float64, error) {
 	// Command to get temperature in Kelvin * 10
 	// MSAcpi_ThermalZoneTemperature is a common WMI class for ACPI thermal zones.
 	// CurrentTemperature is in 0.1 Kelvin.
 	cmd := "Get-CimInstance -Namespace root/wmi -ClassName MSAcpi_ThermalZoneTemperature | Select-Object -ExpandProperty CurrentTemperature | Measure-Object -Maximum | Select-Object -ExpandProperty Maximum"
 
-	output, err := execPowerShell(cmd)
+	output, err := execPowerShell(ctx, cmd)
 	if err != nil {
-		// Fallback or specific error handling can go here.
-		// For now, return error to let caller decide (e.g., fallback to mock).
+		// Return error to let caller decide; the collector records the signal as missing.
 		return 0, err
 	}
 
 	if output == "" {
-		return 0, fmt.Errorf("no temperature data returned")
+		return 0, &ExecError{Kind: ErrNotFound, Command: cmd, Err: fmt.Errorf("no temperature data returned")}
 	}
 
-	// Parse output
-	rawTemp, err := strconv.ParseFloat(output, 64)
+	// Parse output. Measure-Object prints with the user's locale, so accept a decimal comma.
+	rawTemp, err := parseCIMFloat(output)
 	if err != nil {
-		return 0, err
+		return 0, parseError(cmd, err)
 	}
 
 	// Convert Decikelvin to Celsius: (K * 10 - 2732) / 10 = C
@@ -37,3 +38,61 @@ func GetCPUTemperature() (float64, error) {
 
 	return celsius, nil
 }
+
+// thermalZoneQuery lists every ACPI thermal zone with its temperature. Like
+// the maximum above, it needs Administrator rights.
+const thermalZoneQuery = "Get-CimInstance -Namespace root/wmi -ClassName MSAcpi_ThermalZoneTemperature | " +
+	"Select-Object InstanceName,CurrentTemperature | ConvertTo-Json -Compress"
+
+// acpiThermalZone mirrors the fields selected from one MSAcpi_ThermalZoneTemperature instance.
+type acpiThermalZone struct {
+	InstanceName       string
+	CurrentTemperature cimNumber // Tenths of a kelvin
+}
+
+// TempSensors lists the ACPI thermal zones, e.g.
+// "wmi:MSAcpi_ThermalZoneTemperature/ACPI\ThermalZone\CPUZ_0". The hottest
+// zone, which Temperature reports, is often the chipset or an SSD.
+func (p *WMIProvider) TempSensors(ctx context.Context) ([]NamedTemp, error) {
+	output, err := execPowerShell(ctx, thermalZoneQuery)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := parseACPIThermalZones([]byte(output))
+	if err != nil {
+		return nil, parseError(thermalZoneQuery, err)
+	}
+	var list []NamedTemp
+	for _, z := range zones {
+		if z.CurrentTemperature.Valid {
+			list = append(list, NamedTemp{
+				Selector: "wmi:MSAcpi_ThermalZoneTemperature/" + z.InstanceName,
+				TempC:    (z.CurrentTemperature.Value - 2732) / 10.0,
+			})
+		}
+	}
+	return list, nil
+}
+
+// parseACPIThermalZones decodes the zone query output, accepting both a single
+// object and an array.
+func parseACPIThermalZones(output []byte) ([]acpiThermalZone, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, fmt.Errorf("no thermal zones returned")
+	}
+
+	var zones []acpiThermalZone
+	if output[0] == '[' {
+		if err := json.Unmarshal(output, &zones); err != nil {
+			return nil, fmt.Errorf("failed to parse thermal zones: %v", err)
+		}
+	} else {
+		var z acpiThermalZone
+		if err := json.Unmarshal(output, &z); err != nil {
+			return nil, fmt.Errorf("failed to parse thermal zones: %v", err)
+		}
+		zones = append(zones, z)
+	}
+	return zones, nil
+}
@@ -0,0 +1,163 @@
+package sensors
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strings"
+)
+
+// Aggregation rules of a TempMapping.
+const (
+	TempRuleMax   = "max"   // Hottest of all matched sensors
+	TempRuleMean  = "mean"  // Average of all matched sensors
+	TempRuleLabel = "label" // The first selector that matches; the hottest if its pattern matches several
+)
+
+// Selector families: hwmon and zone are Linux hwmon inputs and thermal zones,
+// wmi covers LibreHardwareMonitor/OpenHardwareMonitor sensors and
+// MSAcpi_ThermalZoneTemperature instances, perfcounter the thermal zone counters.
+var selectorFamilies = []string{"hwmon", "zone", "wmi", "perfcounter"}
+
+// NamedTemp is one temperature sensor and the selector naming it,
+// e.g. "hwmon:k10temp/Tctl" or "wmi:LibreHardwareMonitor/intelcpu/0/CPU Package".
+// Selectors need not be unique: two coretemp chips of a dual socket machine
+// both have "hwmon:coretemp/Core 0". Path then tells them apart.
+type NamedTemp struct {
+	Selector string
+	Path     string // Where the reading comes from; "" if the selector is unique
+	TempC    float64
+}
+
+// key identifies the sensor among all others.
+func (s NamedTemp) key() string {
+	if s.Path != "" {
+		return s.Path
+	}
+	return s.Selector
+}
+
+// TempMapping names the sensors the CPU temperature is taken from, for
+// machines where the automatic choice lands on a chipset or SSD sensor.
+// Selectors have the form "family:group/name"; group and name may be shell
+// patterns (k10temp/Tccd*), and a selector without a name matches the whole
+// group. Matching ignores case.
+type TempMapping struct {
+	Selectors []string
+	Rule      string
+}
+
+// ParseTempMapping validates the selectors and rule. An empty rule means max.
+func ParseTempMapping(selectors []string, rule string) (*TempMapping, error) {
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("no temperature sensor selector given")
+	}
+	if rule == "" {
+		rule = TempRuleMax
+	}
+	switch rule {
+	case TempRuleMax, TempRuleMean, TempRuleLabel:
+	default:
+		return nil, fmt.Errorf("unknown temperature rule %q (expected %s, %s or %s)", rule, TempRuleMax, TempRuleMean, TempRuleLabel)
+	}
+	for _, sel := range selectors {
+		family, rest, ok := strings.Cut(sel, ":")
+		if !ok || rest == "" {
+			return nil, fmt.Errorf("invalid sensor selector %q, expected family:group/name (e.g. hwmon:k10temp/Tctl)", sel)
+		}
+		if !containsFold(selectorFamilies, family) {
+			return nil, fmt.Errorf("invalid sensor selector %q: unknown family %q (expected %s)", sel, family, strings.Join(selectorFamilies, ", "))
+		}
+		group, name, _ := strings.Cut(rest, "/")
+		for _, pattern := range []string{group, name} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid sensor selector %q: %v", sel, err)
+			}
+		}
+	}
+	return &TempMapping{Selectors: selectors, Rule: rule}, nil
+}
+
+// String describes the mapping, e.g. "max of hwmon:k10temp/Tccd1, hwmon:k10temp/Tccd2".
+func (m *TempMapping) String() string {
+	return fmt.Sprintf("%s of %s", m.Rule, strings.Join(m.Selectors, ", "))
+}
+
+// Apply picks the temperature from sensors according to the mapping. It also
+// returns the selectors of the sensors used, with the path added where a
+// selector names several sensors.
+func (m *TempMapping) Apply(sensors []NamedTemp) (float64, []string, error) {
+	var used []NamedTemp
+	seen := map[string]bool{}
+	for _, sel := range m.Selectors {
+		var matched []NamedTemp
+		for _, s := range sensors {
+			if !seen[s.key()] && matchSelector(sel, s.Selector) {
+				seen[s.key()] = true
+				matched = append(matched, s)
+			}
+		}
+		if m.Rule == TempRuleLabel && len(matched) > 0 {
+			used = matched
+			break
+		}
+		used = append(used, matched...)
+	}
+	if len(used) == 0 {
+		return 0, nil, fmt.Errorf("no temperature sensor matches %s", strings.Join(m.Selectors, ", "))
+	}
+
+	count := map[string]int{}
+	for _, s := range used {
+		count[s.Selector]++
+	}
+	names := make([]string, len(used))
+	sum, hottest := 0.0, math.Inf(-1)
+	for i, s := range used {
+		names[i] = s.Selector
+		if count[s.Selector] > 1 && s.Path != "" {
+			names[i] = fmt.Sprintf("%s (%s)", s.Selector, s.Path)
+		}
+		sum += s.TempC
+		hottest = math.Max(hottest, s.TempC)
+	}
+	if m.Rule == TempRuleMean {
+		return sum / float64(len(used)), names, nil
+	}
+	return hottest, names, nil
+}
+
+// matchSelector reports whether the selector pattern sel names the sensor key.
+func matchSelector(sel, key string) bool {
+	selFamily, selRest, _ := strings.Cut(sel, ":")
+	keyFamily, keyRest, _ := strings.Cut(key, ":")
+	if !strings.EqualFold(selFamily, keyFamily) {
+		return false
+	}
+	selGroup, selName, hasName := strings.Cut(selRest, "/")
+	keyGroup, keyName, _ := strings.Cut(keyRest, "/")
+	if !hasName {
+		selName = "*"
+	}
+	return matchPart(selGroup, keyGroup) && matchPart(selName, keyName)
+}
+
+// matchPart compares one selector part. Without pattern characters the
+// comparison is literal, since Windows instance names contain backslashes,
+// which path.Match would take as escapes.
+func matchPart(pattern, s string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return strings.EqualFold(pattern, s)
+	}
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(s))
+	return ok
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
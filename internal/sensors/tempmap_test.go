@@ -0,0 +1,75 @@
+package sensors
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTempMappingSharedSelectors(t *testing.T) {
+	// A dual socket machine: two coretemp chips with the same labels, and two
+	// NVMe drives that both call their sensor Composite.
+	root := writeTree(t, map[string]string{
+		"class/hwmon/hwmon0/name":        "coretemp",
+		"class/hwmon/hwmon0/temp2_input": "61000",
+		"class/hwmon/hwmon0/temp2_label": "Core 0",
+		"class/hwmon/hwmon1/name":        "coretemp",
+		"class/hwmon/hwmon1/temp2_input": "75000",
+		"class/hwmon/hwmon1/temp2_label": "Core 0",
+		"class/hwmon/hwmon2/name":        "nvme",
+		"class/hwmon/hwmon2/temp1_input": "40000",
+		"class/hwmon/hwmon2/temp1_label": "Composite",
+		"class/hwmon/hwmon3/name":        "nvme",
+		"class/hwmon/hwmon3/temp1_input": "50000",
+		"class/hwmon/hwmon3/temp1_label": "Composite",
+	})
+	p := NewLinuxProvider()
+	p.Temp.Root = root
+	sensors, err := p.TempSensors(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		selector string
+		rule     string
+		want     float64
+	}{
+		{"hwmon:coretemp/Core 0", TempRuleMax, 75},
+		{"hwmon:coretemp/Core 0", TempRuleMean, 68},
+		{"hwmon:nvme/Composite", TempRuleMean, 45},
+		{"hwmon:nvme", TempRuleMax, 50},
+	}
+	for _, tt := range tests {
+		m, err := ParseTempMapping([]string{tt.selector}, tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, used, err := m.Apply(sensors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s of %s = %v, want %v", tt.rule, tt.selector, got, tt.want)
+		}
+		if len(used) != 2 {
+			t.Errorf("%s used %v, want both sensors", tt.selector, used)
+		}
+	}
+
+	m, err := ParseTempMapping([]string{"hwmon:coretemp/Core 0"}, TempRuleMax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, used, err := m.Apply(sensors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"hwmon:coretemp/Core 0 (" + root + "/class/hwmon/hwmon0/temp2_input)",
+		"hwmon:coretemp/Core 0 (" + root + "/class/hwmon/hwmon1/temp2_input)",
+	}
+	if !reflect.DeepEqual(used, want) {
+		t.Errorf("used = %v, want %v", used, want)
+	}
+}
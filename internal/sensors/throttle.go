@@ -0,0 +1,127 @@
+package sensors
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ThrottleData holds kernel thermal throttle counters. As returned by
+// ThrottleCounters.Sample, the values are increments since the previous sample.
+type ThrottleData struct {
+	CoreEvents    uint64
+	PackageEvents uint64
+	CoreTimeMs    uint64
+	PackageTimeMs uint64
+}
+
+// ThrottleCounters reads cpu*/thermal_throttle/{core,package}_throttle_{count,total_time_ms},
+// which the kernel's x86 thermal interrupt handler maintains. Core counters are
+// shared by SMT siblings and package counters by every CPU in the package, so
+// each core and package is counted once using the topology files.
+// Like ProcStatLoad, the first Sample waits PrimeInterval to have a delta to report.
+type ThrottleCounters struct {
+	Root          string
+	PrimeInterval time.Duration
+
+	mu   sync.Mutex
+	prev *ThrottleData
+}
+
+// NewThrottleCounters returns a reader rooted at DefaultSysfsRoot.
+func NewThrottleCounters() *ThrottleCounters {
+	return &ThrottleCounters{
+		Root:          DefaultSysfsRoot,
+		PrimeInterval: 250 * time.Millisecond,
+	}
+}
+
+// Sample returns how much each counter grew since the previous call.
+func (t *ThrottleCounters) Sample(ctx context.Context) (ThrottleData, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.prev == nil {
+		prev, err := t.Totals()
+		if err != nil {
+			return ThrottleData{}, err
+		}
+		t.prev = &prev
+
+		select {
+		case <-time.After(t.PrimeInterval):
+		case <-ctx.Done():
+			return ThrottleData{}, ctx.Err()
+		}
+	}
+
+	curr, err := t.Totals()
+	if err != nil {
+		return ThrottleData{}, err
+	}
+
+	delta := func(a, b uint64) uint64 {
+		// Totals shrink when CPUs go offline; don't report that as a huge increment.
+		if b < a {
+			return 0
+		}
+		return b - a
+	}
+	data := ThrottleData{
+		CoreEvents:    delta(t.prev.CoreEvents, curr.CoreEvents),
+		PackageEvents: delta(t.prev.PackageEvents, curr.PackageEvents),
+		CoreTimeMs:    delta(t.prev.CoreTimeMs, curr.CoreTimeMs),
+		PackageTimeMs: delta(t.prev.PackageTimeMs, curr.PackageTimeMs),
+	}
+	t.prev = &curr
+	return data, nil
+}
+
+// Totals returns the counters' absolute values summed over cores and packages.
+func (t *ThrottleCounters) Totals() (ThrottleData, error) {
+	dirs, err := filepath.Glob(filepath.Join(t.Root, "devices", "system", "cpu", "cpu[0-9]*", "thermal_throttle"))
+	if err != nil {
+		return ThrottleData{}, err
+	}
+	if len(dirs) == 0 {
+		return ThrottleData{}, fmt.Errorf("no thermal_throttle counters found under %s", t.Root)
+	}
+
+	var data ThrottleData
+	seenCores := make(map[string]bool)
+	seenPackages := make(map[string]bool)
+
+	for _, dir := range dirs {
+		topology := filepath.Join(filepath.Dir(dir), "topology")
+		pkg := readSysfsString(filepath.Join(topology, "physical_package_id"))
+		core := pkg + "/" + readSysfsString(filepath.Join(topology, "core_id"))
+		if pkg == "" {
+			// No topology info: treat every CPU as its own core and package.
+			pkg, core = dir, dir
+		}
+
+		if !seenCores[core] {
+			seenCores[core] = true
+			data.CoreEvents += readCounter(filepath.Join(dir, "core_throttle_count"))
+			data.CoreTimeMs += readCounter(filepath.Join(dir, "core_throttle_total_time_ms"))
+		}
+		if !seenPackages[pkg] {
+			seenPackages[pkg] = true
+			data.PackageEvents += readCounter(filepath.Join(dir, "package_throttle_count"))
+			data.PackageTimeMs += readCounter(filepath.Join(dir, "package_throttle_total_time_ms"))
+		}
+	}
+
+	return data, nil
+}
+
+// readCounter reads a counter file; older kernels lack the *_total_time_ms files.
+func readCounter(path string) uint64 {
+	v, err := readSysfsInt(path)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return uint64(v)
+}
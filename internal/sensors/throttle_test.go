@@ -0,0 +1,74 @@
+package sensors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// throttleCPU returns the sysfs files of one CPU's topology and throttle counters.
+func throttleCPU(cpu, pkg, core, coreCount, pkgCount string) map[string]string {
+	dir := "devices/system/cpu/" + cpu + "/"
+	return map[string]string{
+		dir + "topology/physical_package_id":                 pkg,
+		dir + "topology/core_id":                             core,
+		dir + "thermal_throttle/core_throttle_count":         coreCount,
+		dir + "thermal_throttle/package_throttle_count":      pkgCount,
+		dir + "thermal_throttle/core_throttle_total_time_ms": "0",
+	}
+}
+
+func TestThrottleCountersTotals(t *testing.T) {
+	files := map[string]string{}
+	// cpu0 and cpu1 are SMT siblings; cpu2 is a second core in the same package.
+	for _, cpu := range []map[string]string{
+		throttleCPU("cpu0", "0", "0", "7", "12"),
+		throttleCPU("cpu1", "0", "0", "7", "12"),
+		throttleCPU("cpu2", "0", "1", "3", "12"),
+	} {
+		for k, v := range cpu {
+			files[k] = v
+		}
+	}
+	root := writeTree(t, files)
+
+	got, err := (&ThrottleCounters{Root: root}).Totals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Older kernels have no package_throttle_total_time_ms; it reads as 0.
+	want := ThrottleData{CoreEvents: 10, PackageEvents: 12}
+	if got != want {
+		t.Errorf("Totals() = %+v, want %+v (each core and package counted once)", got, want)
+	}
+}
+
+func TestThrottleCountersSample(t *testing.T) {
+	root := writeTree(t, throttleCPU("cpu0", "0", "0", "4", "9"))
+	tc := &ThrottleCounters{Root: root}
+	if _, err := tc.Sample(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(root, "devices/system/cpu/cpu0/thermal_throttle")
+	for name, v := range map[string]string{"core_throttle_count": "6", "package_throttle_count": "14"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(v+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := tc.Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ThrottleData{CoreEvents: 2, PackageEvents: 5}); got != want {
+		t.Errorf("Sample() = %+v, want %+v", got, want)
+	}
+}
+
+func TestThrottleCountersMissing(t *testing.T) {
+	root := writeTree(t, map[string]string{"devices/system/cpu/cpu0/topology/core_id": "0"})
+	if _, err := (&ThrottleCounters{Root: root}).Totals(); err == nil {
+		t.Error("no error without thermal_throttle directories")
+	}
+}
@@ -0,0 +1,315 @@
+package sensors
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A trace is a recorded sequence of snapshots, one per tick. Two formats are read:
+//
+//   - JSONL: one JSON-encoded Snapshot per line, as written by TraceWriter.
+//     Lossless, including every signal's provenance.
+//   - CSV: a header row naming Snapshot fields (see traceColumns) plus
+//     "timestamp" (RFC 3339) and optional "provenance" and "backend" columns.
+//     Easy to write by hand or export from a spreadsheet. An empty cell
+//     means the signal was missing in that row.
+
+// TraceBackend is the backend name given to signals of a trace that does not name one.
+const TraceBackend = "trace"
+
+// ReadTrace loads a trace file. Files ending in .csv are read as CSV, anything else as JSONL.
+func ReadTrace(path string) ([]*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	trace, err := DecodeTrace(f, path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return trace, nil
+}
+
+// DecodeTrace reads a trace from r; name only selects the format by its extension.
+// Timestamps must not go backwards.
+func DecodeTrace(r io.Reader, name string) ([]*Snapshot, error) {
+	var trace []*Snapshot
+	var err error
+	if strings.EqualFold(filepath.Ext(name), ".csv") {
+		trace, err = decodeTraceCSV(r)
+	} else {
+		trace, err = decodeTraceJSONL(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(trace) == 0 {
+		return nil, fmt.Errorf("trace has no snapshots")
+	}
+	for i := 1; i < len(trace); i++ {
+		if trace[i].Timestamp.Before(trace[i-1].Timestamp) {
+			return nil, fmt.Errorf("snapshot %d: timestamp %s is before the previous one", i+1, trace[i].Timestamp.Format(time.RFC3339))
+		}
+	}
+	return trace, nil
+}
+
+func decodeTraceJSONL(r io.Reader) ([]*Snapshot, error) {
+	var trace []*Snapshot
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var s Snapshot
+		if err := json.Unmarshal([]byte(text), &s); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if s.Timestamp.IsZero() {
+			return nil, fmt.Errorf("line %d: snapshot has no Timestamp", line)
+		}
+		normalizeSources(&s)
+		trace = append(trace, &s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+// normalizeSources rebuilds ValidSignals from Sources so the two cannot disagree.
+// A hand-written snapshot with only ValidSignals gets those signals as measured.
+func normalizeSources(s *Snapshot) {
+	if len(s.Sources) == 0 {
+		valid := s.ValidSignals
+		s.ValidSignals = []string{}
+		for _, name := range valid {
+			s.SetSource(name, ProvenanceMeasured, TraceBackend)
+		}
+		return
+	}
+
+	names := make([]string, 0, len(s.Sources))
+	for name := range s.Sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s.ValidSignals = []string{}
+	for _, name := range names {
+		src := s.Sources[name]
+		s.SetSource(name, src.Provenance, src.Backend)
+	}
+}
+
+// traceColumn is a CSV column: the signal it feeds and how to store its value.
+type traceColumn struct {
+	signal string
+	set    func(s *Snapshot, v string) error
+}
+
+func floatColumn(signal string, field func(s *Snapshot) *float64) traceColumn {
+	return traceColumn{signal, func(s *Snapshot, v string) (err error) {
+		*field(s), err = strconv.ParseFloat(v, 64)
+		return err
+	}}
+}
+
+func intColumn(signal string, field func(s *Snapshot) *int) traceColumn {
+	return traceColumn{signal, func(s *Snapshot, v string) (err error) {
+		*field(s), err = strconv.Atoi(v)
+		return err
+	}}
+}
+
+func uintColumn(signal string, field func(s *Snapshot) *uint64) traceColumn {
+	return traceColumn{signal, func(s *Snapshot, v string) (err error) {
+		*field(s), err = strconv.ParseUint(v, 10, 64)
+		return err
+	}}
+}
+
+func stringColumn(signal string, field func(s *Snapshot) *string) traceColumn {
+	return traceColumn{signal, func(s *Snapshot, v string) error {
+		*field(s) = v
+		return nil
+	}}
+}
+
+// batteryColumn sets the battery charge, which also means there is a battery.
+var batteryColumn = traceColumn{SignalPowerSource, func(s *Snapshot, v string) (err error) {
+	s.BatteryPercent, err = strconv.ParseFloat(v, 64)
+	s.HasBattery = err == nil
+	return err
+}}
+
+// deviceColumn sets the temperature of a device zone.
+func deviceColumn(zone string) traceColumn {
+	return traceColumn{SignalDeviceTemps, func(s *Snapshot, v string) error {
+		temp, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		if s.DeviceTempC == nil {
+			s.DeviceTempC = map[string]float64{}
+		}
+		s.DeviceTempC[zone] = temp
+		return nil
+	}}
+}
+
+// traceColumns are the Snapshot fields a CSV trace can set. Several columns may
+// feed one signal; the signal is present when any of them has a value.
+var traceColumns = map[string]traceColumn{
+	"TempC":                  floatColumn(SignalTemp, func(s *Snapshot) *float64 { return &s.TempC }),
+	"FreqMHz":                intColumn(SignalFreq, func(s *Snapshot) *int { return &s.FreqMHz }),
+	"MaxFreqMHz":             intColumn(SignalFreq, func(s *Snapshot) *int { return &s.MaxFreqMHz }),
+	"BaseFreqMHz":            intColumn(SignalBaseFreq, func(s *Snapshot) *int { return &s.BaseFreqMHz }),
+	"LoadPercent":            floatColumn(SignalLoad, func(s *Snapshot) *float64 { return &s.LoadPercent }),
+	"StealPercent":           floatColumn(SignalLoad, func(s *Snapshot) *float64 { return &s.StealPercent }),
+	"PowerW":                 floatColumn(SignalPower, func(s *Snapshot) *float64 { return &s.PowerW }),
+	"CoreThrottleEvents":     uintColumn(SignalThrottle, func(s *Snapshot) *uint64 { return &s.CoreThrottleEvents }),
+	"PackageThrottleEvents":  uintColumn(SignalThrottle, func(s *Snapshot) *uint64 { return &s.PackageThrottleEvents }),
+	"CgroupThrottledPeriods": uintColumn(SignalQuota, func(s *Snapshot) *uint64 { return &s.CgroupThrottledPeriods }),
+	"CgroupPeriods":          uintColumn(SignalQuota, func(s *Snapshot) *uint64 { return &s.CgroupPeriods }),
+	"PSISomeAvg10":           floatColumn(SignalPressure, func(s *Snapshot) *float64 { return &s.PSISomeAvg10 }),
+	"ZonePassiveLimit":       floatColumn(SignalZones, func(s *Snapshot) *float64 { return &s.ZonePassiveLimit }),
+	"PowerSource":            stringColumn(SignalPowerSource, func(s *Snapshot) *string { return &s.PowerSource }),
+	"BatteryPercent":         batteryColumn,
+	"NVMeTempC":              deviceColumn(DeviceNVMe),
+	"SoCTempC":               deviceColumn(DeviceSoC),
+	"BatteryTempC":           deviceColumn(DeviceBattery),
+}
+
+func decodeTraceCSV(r io.Reader) ([]*Snapshot, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %v", err)
+	}
+	timeCol, provCol, backendCol := -1, -1, -1
+	var signals []string // Signals named by the header, in column order
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		header[i] = name
+		switch name {
+		case "timestamp":
+			timeCol = i
+		case "provenance":
+			provCol = i
+		case "backend":
+			backendCol = i
+		default:
+			col, ok := traceColumns[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown CSV column %q", name)
+			}
+			if !contains(signals, col.signal) {
+				signals = append(signals, col.signal)
+			}
+		}
+	}
+	if timeCol < 0 {
+		return nil, fmt.Errorf("CSV trace needs a timestamp column")
+	}
+
+	var trace []*Snapshot
+	for row := 2; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s := &Snapshot{ValidSignals: []string{}, Sources: map[string]SignalSource{}}
+		s.Timestamp, err = time.Parse(time.RFC3339Nano, record[timeCol])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		prov, backend := ProvenanceMeasured, TraceBackend
+		if provCol >= 0 && record[provCol] != "" {
+			switch p := Provenance(record[provCol]); p {
+			case ProvenanceMeasured, ProvenanceEstimated, ProvenanceStale, ProvenanceMocked:
+				prov = p
+			default:
+				return nil, fmt.Errorf("row %d: invalid provenance %q", row, p)
+			}
+		}
+		if backendCol >= 0 && record[backendCol] != "" {
+			backend = record[backendCol]
+		}
+
+		present := map[string]bool{}
+		for i, v := range record {
+			col, ok := traceColumns[header[i]]
+			if !ok || v == "" {
+				continue
+			}
+			if err := col.set(s, v); err != nil {
+				return nil, fmt.Errorf("row %d, column %s: %v", row, header[i], err)
+			}
+			present[col.signal] = true
+		}
+		for _, signal := range signals {
+			if present[signal] {
+				s.SetSource(signal, prov, backend)
+			} else {
+				s.SetSource(signal, ProvenanceMissing, backend)
+			}
+		}
+		trace = append(trace, s)
+	}
+	return trace, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// TraceWriter records snapshots as a JSONL trace.
+type TraceWriter struct {
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewTraceWriter creates (or truncates) a trace file.
+func NewTraceWriter(path string) (*TraceWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &TraceWriter{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Write appends one snapshot.
+func (w *TraceWriter) Write(s *Snapshot) error {
+	return w.enc.Encode(s)
+}
+
+func (w *TraceWriter) Close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,106 @@
+package sensors
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeTraceJSONL(t *testing.T) {
+	text := `{"Timestamp":"2024-05-01T10:00:00Z","TempC":71.5,"FreqMHz":3200,"ValidSignals":["TempC","FreqMHz"]}
+
+{"Timestamp":"2024-05-01T10:00:01Z","TempC":93,"FreqMHz":2100,"Sources":{"TempC":{"Provenance":"measured","Backend":"hwmon"},"FreqMHz":{"Provenance":"stale","Backend":"hwmon"},"LoadPercent":{"Provenance":"missing","Backend":"hwmon"}}}
+`
+	trace, err := DecodeTrace(strings.NewReader(text), "run.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(trace))
+	}
+
+	// Only ValidSignals given: the signals count as measured by the trace.
+	first := trace[0]
+	if first.TempC != 71.5 || first.FreqMHz != 3200 {
+		t.Errorf("first snapshot = %.1f°C %d MHz, want 71.5°C 3200 MHz", first.TempC, first.FreqMHz)
+	}
+	if got := first.Source(SignalTemp); got.Provenance != ProvenanceMeasured || got.Backend != TraceBackend {
+		t.Errorf("first temp source = %v, want measured by %s", got, TraceBackend)
+	}
+
+	// Sources given: ValidSignals is rebuilt from them.
+	second := trace[1]
+	if got := strings.Join(second.ValidSignals, ","); got != "FreqMHz,TempC" {
+		t.Errorf("ValidSignals = %s, want FreqMHz,TempC", got)
+	}
+	if second.HasSignal(SignalLoad) {
+		t.Error("missing load counted as valid")
+	}
+	if got := second.Source(SignalFreq).Provenance; got != ProvenanceStale {
+		t.Errorf("freq provenance = %v, want stale", got)
+	}
+}
+
+func TestDecodeTraceCSV(t *testing.T) {
+	text := `# recorded on a laptop
+timestamp, TempC, FreqMHz, LoadPercent, NVMeTempC, provenance, backend
+2024-05-01T10:00:00Z, 70, 3300, 95, 45, , lhm
+2024-05-01T10:00:01.5Z, 96, , 97, , estimated,
+`
+	trace, err := DecodeTrace(strings.NewReader(text), "run.CSV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(trace))
+	}
+
+	first := trace[0]
+	if first.TempC != 70 || first.FreqMHz != 3300 || first.LoadPercent != 95 {
+		t.Errorf("first row = %+v", first)
+	}
+	if got := first.DeviceTempC[DeviceNVMe]; got != 45 {
+		t.Errorf("NVMe temperature = %v, want 45", got)
+	}
+	if got := first.Source(SignalTemp); got.Provenance != ProvenanceMeasured || got.Backend != "lhm" {
+		t.Errorf("temp source = %v, want measured by lhm", got)
+	}
+
+	second := trace[1]
+	if want := time.Date(2024, 5, 1, 10, 0, 1, 5e8, time.UTC); !second.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", second.Timestamp, want)
+	}
+	if got := second.Source(SignalTemp); got.Provenance != ProvenanceEstimated || got.Backend != TraceBackend {
+		t.Errorf("temp source = %v, want estimated by %s", got, TraceBackend)
+	}
+	// Empty cells mean missing signals.
+	if second.HasSignal(SignalFreq) || second.HasSignal(SignalDeviceTemps) {
+		t.Errorf("empty cells counted as valid: %v", second.ValidSignals)
+	}
+	if got := second.Source(SignalFreq).Provenance; got != ProvenanceMissing {
+		t.Errorf("freq provenance = %v, want missing", got)
+	}
+}
+
+func TestDecodeTraceErrors(t *testing.T) {
+	tests := []struct {
+		name, file, text, want string
+	}{
+		{"empty", "a.jsonl", "\n", "no snapshots"},
+		{"no timestamp", "a.jsonl", `{"TempC":70}`, "no Timestamp"},
+		{"backwards", "a.jsonl", `{"Timestamp":"2024-05-01T10:00:05Z"}
+{"Timestamp":"2024-05-01T10:00:01Z"}`, "before the previous one"},
+		{"unknown column", "a.csv", "timestamp,Wattage\n2024-05-01T10:00:00Z,5\n", `unknown CSV column "Wattage"`},
+		{"no timestamp column", "a.csv", "TempC\n70\n", "needs a timestamp column"},
+		{"bad value", "a.csv", "timestamp,TempC\n2024-05-01T10:00:00Z,hot\n", "row 2, column TempC"},
+		{"bad provenance", "a.csv", "timestamp,TempC,provenance\n2024-05-01T10:00:00Z,70,guessed\n", `invalid provenance "guessed"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeTrace(strings.NewReader(tt.text), tt.file)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,44 +1,67 @@
 package sensors
 
 import (
-	"bytes"
-	"fmt"
+	"context"
 	"os/exec"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
-// execPowerShell executes a PowerShell command and returns the output as a string.
-// It enforces a timeout to prevent hanging.
-func execPowerShell(command string) (string, error) {
-	// 5 second timeout for any sensor read
-	ctxTimeout := 5 * time.Second
-
-	// Create command with timeout context would be ideal, but for simplicity/compatibility:
-	// using time.AfterFunc or just simple exec for now to keep it dependency-free and simple.
-
-	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", command)
-
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	// Simple timeout mechanism
-	done := make(chan error, 1)
-	go func() {
-		done <- cmd.Run()
-	}()
-
-	select {
-	case <-time.After(ctxTimeout):
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		return "", fmt.Errorf("timeout executing powershell command")
-	case err := <-done:
-		if err != nil {
-			return "", err
-		}
+// WMIProvider reads sensors on Windows through PowerShell CIM queries.
+type WMIProvider struct {
+	processors processorCache // Shared Win32_Processor result for Frequency and Load
+}
+
+// NewWMIProvider returns the Windows WMI backend.
+func NewWMIProvider() *WMIProvider {
+	return &WMIProvider{}
+}
+
+func (p *WMIProvider) Name() string { return "wmi" }
+
+func (p *WMIProvider) Available() bool {
+	if runtime.GOOS != "windows" {
+		return false
 	}
+	_, err := exec.LookPath("powershell")
+	return err == nil
+}
 
-	return strings.TrimSpace(out.String()), nil
+// executor runs every WMI query. By default it is one persistent PowerShell
+// session shared by all queries, so at most DefaultPSWorkers PowerShell
+// processes are ever running.
+var (
+	executorMu sync.Mutex
+	executor   Executor = NewPSSession()
+)
+
+// SetExecutor replaces the executor used for PowerShell queries, e.g. with a
+// ReplayExecutor serving recorded fixtures. It returns the previous executor.
+func SetExecutor(e Executor) Executor {
+	executorMu.Lock()
+	defer executorMu.Unlock()
+	prev := executor
+	executor = e
+	return prev
+}
+
+// execPowerShell runs a PowerShell command through the current executor and returns its output.
+// It gives up when ctx is done, and enforces its own timeout to prevent hanging
+// when ctx has no deadline.
+func execPowerShell(ctx context.Context, command string) (string, error) {
+	// 5 second timeout for any sensor read
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	executorMu.Lock()
+	e := executor
+	executorMu.Unlock()
+
+	output, err := e.Run(ctx, command)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
 }